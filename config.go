@@ -0,0 +1,291 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	cli "github.com/urfave/cli/v2"
+)
+
+// CONFIG
+
+// config is the CLI config file, by default ~/.config/dokploy/config.yaml:
+//
+//	current: prod
+//	profiles:
+//	  prod:
+//	    url: https://dokploy.example.com
+//	    key: ...
+type config struct {
+	Current  string              `yaml:"current,omitempty"`
+	Profiles map[string]*profile `yaml:"profiles,omitempty"`
+}
+
+// profile is a named Dokploy instance and the API key to use with it.
+type profile struct {
+	URL string `yaml:"url"`
+	Key string `yaml:"key,omitempty"`
+}
+
+// defaultConfigPath returns $XDG_CONFIG_HOME/dokploy/config.yaml, falling
+// back to ~/.config/dokploy/config.yaml.
+func defaultConfigPath() string {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "dokploy", "config.yaml")
+}
+
+// configPath returns the config file selected by --config.
+func configPath(c *cli.Context) (string, error) {
+	path := c.String("config")
+	if path == "" {
+		path = defaultConfigPath()
+	}
+	if path == "" {
+		return "", errors.New("cannot locate the config file: no home directory; pass --config")
+	}
+	return path, nil
+}
+
+// loadConfig reads the config file at path. A missing file is an empty
+// config.
+func loadConfig(path string) (*config, error) {
+	cfg := &config{Profiles: map[string]*profile{}}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	if cfg.Profiles == nil {
+		cfg.Profiles = map[string]*profile{}
+	}
+	return cfg, nil
+}
+
+// save writes the config to path, readable only by the current user since
+// it holds API keys. The file is replaced atomically.
+func (cfg *config) save(path string) error {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(cfg); err != nil {
+		return err
+	}
+	if err := enc.Close(); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".config-*.yaml")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(buf.Bytes()); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0o600); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// resolveConnection returns the URL and API key to connect with. url and key
+// come from --url/--key or DOKPLOY_URL/DOKPLOY_API_KEY and take precedence
+// over the profile named by profileName, or the current profile when
+// profileName is empty. A profile's key is only used with that profile's URL,
+// so it is never sent to a server given on the command line. Without any of
+// those, the key is looked up with stored, which returns "" if none is
+// stored for the URL.
+func resolveConnection(url, key, profileName string, cfg *config, stored func(url string) (string, error)) (string, string, error) {
+	if url != "" && key != "" {
+		return url, key, nil
+	}
+
+	name := profileName
+	if name == "" {
+		name = cfg.Current
+	}
+	if name != "" {
+		p, ok := cfg.Profiles[name]
+		if !ok {
+			return "", "", fmt.Errorf("profile %q not found; see dokploy profile list", name)
+		}
+		if url == "" {
+			url = p.URL
+		}
+		if key == "" && strings.TrimRight(url, "/") == strings.TrimRight(p.URL, "/") {
+			key = p.Key
+		}
+	}
+	if url != "" && key == "" && stored != nil {
+		var err error
+		if key, err = stored(url); err != nil {
+			return "", "", err
+		}
+	}
+
+	switch {
+	case url == "":
+		return "", "", errors.New("no Dokploy URL: pass --url, set DOKPLOY_URL, or add a profile with dokploy profile add")
+	case key == "":
+		return "", "", fmt.Errorf("no API key for %s: run dokploy login, pass --key, or set DOKPLOY_API_KEY", url)
+	}
+	return url, key, nil
+}
+
+// PROFILE COMMANDS
+
+// profileResult is an entry of profile list. The API key is never printed.
+type profileResult struct {
+	Name    string `json:"name"`
+	URL     string `json:"url"`
+	Current bool   `json:"current"`
+	HasKey  bool   `json:"hasKey"`
+}
+
+func profileCommand() *cli.Command {
+	nameFlag := &cli.StringFlag{Name: "name", Usage: "Profile name", Required: true}
+	return &cli.Command{
+		Name:  "profile",
+		Usage: "Manage named Dokploy connection profiles in the config file",
+		Subcommands: []*cli.Command{
+			{
+				Name:  "add",
+				Usage: "Add a profile, or replace the one with the same name",
+				Flags: []cli.Flag{
+					nameFlag,
+					&cli.StringFlag{Name: "url", Usage: "Dokploy base API URL", Required: true},
+					&cli.StringFlag{Name: "key", Usage: "Dokploy API key (omit it and run dokploy login to keep the key in the keyring)"},
+					&cli.BoolFlag{Name: "use", Usage: "Make it the current profile (the first profile always is)"},
+				},
+				Action: func(c *cli.Context) error {
+					return updateConfig(c, func(cfg *config) (output, error) {
+						name := c.String("name")
+						cfg.Profiles[name] = &profile{URL: strings.TrimRight(c.String("url"), "/"), Key: c.String("key")}
+						if c.Bool("use") || cfg.Current == "" {
+							cfg.Current = name
+						}
+						return actionOutput("profile", name, "saved", "Saved profile "+name), nil
+					})
+				},
+			},
+			{
+				Name:  "list",
+				Usage: "List profiles; the current one is marked with *",
+				Action: func(c *cli.Context) error {
+					path, err := configPath(c)
+					if err != nil {
+						return err
+					}
+					cfg, err := loadConfig(path)
+					if err != nil {
+						return err
+					}
+					names := make([]string, 0, len(cfg.Profiles))
+					for name := range cfg.Profiles {
+						names = append(names, name)
+					}
+					slices.Sort(names)
+
+					profiles := make([]profileResult, 0, len(names))
+					out := output{
+						Columns: []string{"CURRENT", "NAME", "URL"},
+						Rows:    [][]string{},
+					}
+					for _, name := range names {
+						p := cfg.Profiles[name]
+						current := name == cfg.Current
+						profiles = append(profiles, profileResult{Name: name, URL: p.URL, Current: current, HasKey: p.Key != ""})
+						mark := ""
+						if current {
+							mark = "*"
+						}
+						out.IDs = append(out.IDs, name)
+						out.Rows = append(out.Rows, []string{mark, name, p.URL})
+					}
+					out.Data = profiles
+					return printOutput(c, out)
+				},
+			},
+			{
+				Name:  "use",
+				Usage: "Make a profile the current one",
+				Flags: []cli.Flag{nameFlag},
+				Action: func(c *cli.Context) error {
+					return updateConfig(c, func(cfg *config) (output, error) {
+						name := c.String("name")
+						if _, ok := cfg.Profiles[name]; !ok {
+							return output{}, fmt.Errorf("profile %q not found", name)
+						}
+						cfg.Current = name
+						return actionOutput("profile", name, "selected", "Using profile "+name), nil
+					})
+				},
+			},
+			{
+				Name:  "remove",
+				Usage: "Remove a profile",
+				Flags: []cli.Flag{nameFlag},
+				Action: func(c *cli.Context) error {
+					return updateConfig(c, func(cfg *config) (output, error) {
+						name := c.String("name")
+						if _, ok := cfg.Profiles[name]; !ok {
+							return output{}, fmt.Errorf("profile %q not found", name)
+						}
+						delete(cfg.Profiles, name)
+						if cfg.Current == name {
+							cfg.Current = ""
+						}
+						return actionOutput("profile", name, "deleted", "Removed profile "+name), nil
+					})
+				},
+			},
+		},
+	}
+}
+
+// updateConfig loads the config file, applies change and saves it, then
+// prints the output of change.
+func updateConfig(c *cli.Context, change func(*config) (output, error)) error {
+	path, err := configPath(c)
+	if err != nil {
+		return err
+	}
+	cfg, err := loadConfig(path)
+	if err != nil {
+		return err
+	}
+	out, err := change(cfg)
+	if err != nil {
+		return err
+	}
+	if err := cfg.save(path); err != nil {
+		return err
+	}
+	return printOutput(c, out)
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolveConnection_Precedence(t *testing.T) {
+	cfg := &config{
+		Current: "prod",
+		Profiles: map[string]*profile{
+			"prod": {URL: "https://prod.example.com", Key: "prod-key"},
+			"dev":  {URL: "https://dev.example.com", Key: "dev-key"},
+		},
+	}
+	cases := []struct {
+		name, url, key, profile string
+		wantURL, wantKey        string
+	}{
+		{name: "current profile", wantURL: "https://prod.example.com", wantKey: "prod-key"},
+		{name: "named profile", profile: "dev", wantURL: "https://dev.example.com", wantKey: "dev-key"},
+		{name: "flags win", url: "https://other.example.com", key: "other-key", profile: "dev", wantURL: "https://other.example.com", wantKey: "other-key"},
+		{name: "key overrides profile key", key: "override", wantURL: "https://prod.example.com", wantKey: "override"},
+		{name: "same url uses profile key", url: "https://prod.example.com/", wantURL: "https://prod.example.com/", wantKey: "prod-key"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			url, key, err := resolveConnection(tc.url, tc.key, tc.profile, cfg, nil)
+			if err != nil {
+				t.Fatalf("resolveConnection error: %v", err)
+			}
+			if url != tc.wantURL || key != tc.wantKey {
+				t.Errorf("resolveConnection = %q, %q, want %q, %q", url, key, tc.wantURL, tc.wantKey)
+			}
+		})
+	}
+}
+
+func TestResolveConnection_Errors(t *testing.T) {
+	cfg := &config{Profiles: map[string]*profile{"prod": {URL: "https://prod.example.com", Key: "prod-key"}}}
+
+	if _, _, err := resolveConnection("", "", "", cfg, nil); err == nil {
+		t.Errorf("no url and no profile: err = nil, want error")
+	}
+	if _, _, err := resolveConnection("", "", "staging", cfg, nil); err == nil {
+		t.Errorf("unknown profile: err = nil, want error")
+	}
+	// The profile's key must not be sent to a different server.
+	if _, _, err := resolveConnection("https://evil.example.com", "", "prod", cfg, nil); err == nil {
+		t.Errorf("url differs from profile: err = nil, want error")
+	}
+}
+
+func TestConfig_SaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dokploy", "config.yaml")
+
+	cfg, err := loadConfig(path)
+	if err != nil {
+		t.Fatalf("loadConfig (missing file) error: %v", err)
+	}
+	cfg.Current = "dev"
+	cfg.Profiles["dev"] = &profile{URL: "https://dev.example.com", Key: "dev-key"}
+	if err := cfg.save(path); err != nil {
+		t.Fatalf("save error: %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("stat error: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("config file mode = %v, want 0600", perm)
+	}
+
+	got, err := loadConfig(path)
+	if err != nil {
+		t.Fatalf("loadConfig error: %v", err)
+	}
+	if got.Current != "dev" || got.Profiles["dev"] == nil || got.Profiles["dev"].Key != "dev-key" {
+		t.Errorf("loaded config = %+v, want current dev with its key", got)
+	}
+}
+
+func TestResolveConnection_UsesStoredKey(t *testing.T) {
+	cfg := &config{Current: "prod", Profiles: map[string]*profile{"prod": {URL: "https://prod.example.com"}}}
+	var lookedUp string
+	stored := func(url string) (string, error) {
+		lookedUp = url
+		return "stored-key", nil
+	}
+
+	url, key, err := resolveConnection("", "", "", cfg, stored)
+	if err != nil {
+		t.Fatalf("resolveConnection error: %v", err)
+	}
+	if url != "https://prod.example.com" || key != "stored-key" || lookedUp != url {
+		t.Errorf("resolveConnection = %q, %q (looked up %q), want the profile URL with the stored key", url, key, lookedUp)
+	}
+
+	if _, key, _ := resolveConnection("https://prod.example.com", "flag-key", "", cfg, stored); key != "flag-key" {
+		t.Errorf("key = %q, want the --key value over the stored key", key)
+	}
+}
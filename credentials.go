@@ -0,0 +1,397 @@
+package main
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/saurabh-git-dev/dokploy-cli/dokploy"
+	"github.com/zalando/go-keyring"
+	"golang.org/x/term"
+
+	cli "github.com/urfave/cli/v2"
+)
+
+// CREDENTIALS
+
+// API keys saved by login are stored per Dokploy URL in the system keyring
+// (Secret Service on Linux, Keychain on macOS, Credential Manager on
+// Windows). Where no keyring is available they go to an encrypted file next
+// to the config file instead.
+
+const keyringService = "dokploy-cli"
+
+// credentialKey is the name a key is stored under: its URL without a
+// trailing slash.
+func credentialKey(url string) string {
+	return strings.TrimRight(url, "/")
+}
+
+// credentialsPath returns the encrypted credentials file, which lives next
+// to the config file.
+func credentialsPath(c *cli.Context) (string, error) {
+	path, err := configPath(c)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(path), "credentials.json"), nil
+}
+
+// storedKeyLookup returns a function that finds the key stored for a URL,
+// first in the keyring and then in the encrypted file. It returns "" when
+// no key is stored.
+func storedKeyLookup(c *cli.Context) func(url string) (string, error) {
+	return func(url string) (string, error) {
+		if key, err := keyring.Get(keyringService, credentialKey(url)); err == nil {
+			return key, nil
+		}
+		path, err := credentialsPath(c)
+		if err != nil {
+			return "", err
+		}
+		return credentialFile{path: path, passphrase: promptPassphrase}.get(url)
+	}
+}
+
+// credentialFile stores API keys encrypted with AES-256-GCM under a key
+// derived from a passphrase. Each entry has its own salt, so entries saved
+// with different passphrases do not interfere. URLs are stored in the clear
+// so a passphrase is only asked for when there is a key to decrypt.
+type credentialFile struct {
+	path       string
+	passphrase func(path string) (string, error)
+}
+
+const (
+	credentialSaltSize   = 16
+	credentialIterations = 600_000
+)
+
+type credentialFileData struct {
+	Keys map[string]string `json:"keys"` // URL -> base64(salt | nonce | ciphertext)
+}
+
+func (f credentialFile) load() (*credentialFileData, error) {
+	data := &credentialFileData{Keys: map[string]string{}}
+	b, err := os.ReadFile(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return data, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, data); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", f.path, err)
+	}
+	if data.Keys == nil {
+		data.Keys = map[string]string{}
+	}
+	return data, nil
+}
+
+func (f credentialFile) save(data *credentialFileData) error {
+	b, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(f.path), 0o700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(f.path), ".credentials-*.json")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0o600); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), f.path)
+}
+
+// get returns the key stored for url, or "" if there is none.
+func (f credentialFile) get(url string) (string, error) {
+	data, err := f.load()
+	if err != nil {
+		return "", err
+	}
+	entry, ok := data.Keys[credentialKey(url)]
+	if !ok {
+		return "", nil
+	}
+	raw, err := base64.StdEncoding.DecodeString(entry)
+	if err != nil || len(raw) < credentialSaltSize {
+		return "", fmt.Errorf("%s: corrupt entry for %s", f.path, url)
+	}
+	passphrase, err := f.passphrase(f.path)
+	if err != nil {
+		return "", err
+	}
+	gcm, err := credentialCipher(passphrase, raw[:credentialSaltSize])
+	if err != nil {
+		return "", err
+	}
+	sealed := raw[credentialSaltSize:]
+	if len(sealed) < gcm.NonceSize() {
+		return "", fmt.Errorf("%s: corrupt entry for %s", f.path, url)
+	}
+	// The URL is authenticated with the key, so an entry cannot be moved
+	// to another URL.
+	key, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], []byte(credentialKey(url)))
+	if err != nil {
+		return "", fmt.Errorf("cannot decrypt the key for %s in %s: wrong passphrase?", url, f.path)
+	}
+	return string(key), nil
+}
+
+func (f credentialFile) set(url, key string) error {
+	data, err := f.load()
+	if err != nil {
+		return err
+	}
+	passphrase, err := f.passphrase(f.path)
+	if err != nil {
+		return err
+	}
+	salt := make([]byte, credentialSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return err
+	}
+	gcm, err := credentialCipher(passphrase, salt)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	raw := append(salt, nonce...)
+	raw = gcm.Seal(raw, nonce, []byte(key), []byte(credentialKey(url)))
+	data.Keys[credentialKey(url)] = base64.StdEncoding.EncodeToString(raw)
+	return f.save(data)
+}
+
+// delete removes the key stored for url and reports whether there was one.
+func (f credentialFile) delete(url string) (bool, error) {
+	data, err := f.load()
+	if err != nil {
+		return false, err
+	}
+	if _, ok := data.Keys[credentialKey(url)]; !ok {
+		return false, nil
+	}
+	delete(data.Keys, credentialKey(url))
+	return true, f.save(data)
+}
+
+func credentialCipher(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key, err := pbkdf2.Key(sha256.New, passphrase, salt, credentialIterations, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// promptPassphrase returns DOKPLOY_PASSPHRASE, or asks for the passphrase
+// of the credentials file on the terminal.
+func promptPassphrase(path string) (string, error) {
+	if p := os.Getenv("DOKPLOY_PASSPHRASE"); p != "" {
+		return p, nil
+	}
+	p, err := readSecret(fmt.Sprintf("Passphrase for %s: ", path))
+	if err != nil {
+		return "", fmt.Errorf("%w; set DOKPLOY_PASSPHRASE to unlock %s", err, path)
+	}
+	if p == "" {
+		return "", errors.New("empty passphrase")
+	}
+	return p, nil
+}
+
+// readSecret prompts on stderr and reads a line from the terminal without
+// echoing it.
+func readSecret(prompt string) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return "", errors.New("stdin is not a terminal")
+	}
+	fmt.Fprint(os.Stderr, prompt)
+	b, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(b)), nil
+}
+
+// LOGIN COMMANDS
+
+// loginURL returns the URL login and logout act on: --url or DOKPLOY_URL,
+// else the URL of the selected profile. It also returns the selected
+// profile's name, if any.
+func loginURL(c *cli.Context, cfg *config) (url, profileName string, err error) {
+	profileName = c.String("profile")
+	if profileName == "" {
+		profileName = cfg.Current
+	}
+	url = c.String("url")
+	if url == "" {
+		p, ok := cfg.Profiles[profileName]
+		if !ok {
+			return "", "", errors.New("no Dokploy URL: pass --url, set DOKPLOY_URL, or select a profile with --profile")
+		}
+		url = p.URL
+	}
+	return credentialKey(url), profileName, nil
+}
+
+func loginCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "login",
+		Usage: "Check an API key against Dokploy and store it in the system keyring",
+		Description: "The key is read from --key-stdin, from --key / DOKPLOY_API_KEY, or asked for on the terminal.\n" +
+			"It is stored for the URL given by --url, or that of the selected profile; --profile with --url\n" +
+			"also creates that profile. Without a keyring, the key is stored in an encrypted file instead.",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{Name: "key-stdin", Usage: "Read the API key from stdin"},
+			&cli.BoolFlag{Name: "no-keyring", Usage: "Store the key in the encrypted file even if a keyring is available"},
+		},
+		Action: func(c *cli.Context) error {
+			path, err := configPath(c)
+			if err != nil {
+				return err
+			}
+			cfg, err := loadConfig(path)
+			if err != nil {
+				return err
+			}
+			url, profileName, err := loginURL(c, cfg)
+			if err != nil {
+				return err
+			}
+
+			key := c.String("key")
+			switch {
+			case c.Bool("key-stdin"):
+				line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+				if err != nil && !errors.Is(err, io.EOF) {
+					return err
+				}
+				key = strings.TrimSpace(line)
+			case key == "":
+				key, err = readSecret(fmt.Sprintf("API key for %s: ", url))
+				if err != nil {
+					return fmt.Errorf("%w; pass --key-stdin to read the key from stdin", err)
+				}
+			}
+			if key == "" {
+				return errors.New("empty API key")
+			}
+
+			client, err := newClient(c, url, key)
+			if err != nil {
+				return err
+			}
+			if _, err := dokploy.ListProjects(c.Context, client); err != nil {
+				return fmt.Errorf("checking the API key: %w", err)
+			}
+
+			where := "the system keyring"
+			if c.Bool("no-keyring") {
+				err = errors.New("disabled by --no-keyring")
+			} else {
+				err = keyring.Set(keyringService, url, key)
+			}
+			if err != nil {
+				credPath, perr := credentialsPath(c)
+				if perr != nil {
+					return perr
+				}
+				if !c.Bool("no-keyring") {
+					fmt.Fprintf(os.Stderr, "System keyring unavailable (%v); using an encrypted file\n", err)
+				}
+				if err := (credentialFile{path: credPath, passphrase: promptPassphrase}).set(url, key); err != nil {
+					return err
+				}
+				where = credPath
+			}
+
+			// The profile now gets its key from the store; do not keep a
+			// plaintext copy, and remember the URL for a new profile.
+			if profileName != "" {
+				p, ok := cfg.Profiles[profileName]
+				switch {
+				case !ok && c.String("profile") != "":
+					cfg.Profiles[profileName] = &profile{URL: url}
+					if cfg.Current == "" {
+						cfg.Current = profileName
+					}
+				case ok && credentialKey(p.URL) == url:
+					p.Key = ""
+				}
+				if err := cfg.save(path); err != nil {
+					return err
+				}
+			}
+
+			return printOutput(c, actionOutput("credential", url, "stored", fmt.Sprintf("Logged in to %s; API key stored in %s", url, where)))
+		},
+	}
+}
+
+func logoutCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "logout",
+		Usage: "Remove the API key stored by login",
+		Action: func(c *cli.Context) error {
+			path, err := configPath(c)
+			if err != nil {
+				return err
+			}
+			cfg, err := loadConfig(path)
+			if err != nil {
+				return err
+			}
+			url, _, err := loginURL(c, cfg)
+			if err != nil {
+				return err
+			}
+
+			removed := keyring.Delete(keyringService, url) == nil
+			credPath, err := credentialsPath(c)
+			if err != nil {
+				return err
+			}
+			inFile, err := credentialFile{path: credPath}.delete(url)
+			if err != nil {
+				return err
+			}
+			if !removed && !inFile {
+				return fmt.Errorf("no stored API key for %s", url)
+			}
+			return printOutput(c, actionOutput("credential", url, "deleted", "Logged out of "+url))
+		},
+	}
+}
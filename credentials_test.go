@@ -0,0 +1,39 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestCredentialFile_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "credentials.json")
+	passphrase := "correct horse"
+	f := credentialFile{path: path, passphrase: func(string) (string, error) { return passphrase, nil }}
+
+	if key, err := f.get("https://dokploy.example.com"); err != nil || key != "" {
+		t.Fatalf("get (missing file) = %q, %v, want empty", key, err)
+	}
+	if err := f.set("https://dokploy.example.com/", "secret-key"); err != nil {
+		t.Fatalf("set error: %v", err)
+	}
+	key, err := f.get("https://dokploy.example.com")
+	if err != nil {
+		t.Fatalf("get error: %v", err)
+	}
+	if key != "secret-key" {
+		t.Errorf("key = %q, want %q", key, "secret-key")
+	}
+
+	passphrase = "wrong"
+	if _, err := f.get("https://dokploy.example.com"); err == nil {
+		t.Errorf("get with the wrong passphrase: err = nil, want error")
+	}
+
+	removed, err := f.delete("https://dokploy.example.com")
+	if err != nil || !removed {
+		t.Fatalf("delete = %v, %v, want true", removed, err)
+	}
+	if key, err := f.get("https://dokploy.example.com"); err != nil || key != "" {
+		t.Errorf("get after delete = %q, %v, want empty", key, err)
+	}
+}
@@ -0,0 +1,245 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	neturl "net/url"
+	"strings"
+	"time"
+
+	"github.com/saurabh-git-dev/dokploy-cli/dokploy"
+
+	cli "github.com/urfave/cli/v2"
+)
+
+// DIAGNOSTIC COMMANDS
+
+// Statuses of a doctor check.
+const (
+	checkOK   = "ok"
+	checkWarn = "warn"
+	checkFail = "fail"
+	checkSkip = "skip"
+)
+
+// doctorCheck is one line of the doctor checklist.
+type doctorCheck struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Detail string `json:"detail,omitempty"`
+	Hint   string `json:"hint,omitempty"`
+}
+
+func doctorCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "doctor",
+		Usage: "Check the URL, TLS, API key, server version and key permissions (exits 1 if a check fails)",
+		Flags: []cli.Flag{
+			&cli.DurationFlag{Name: "timeout", Usage: "Timeout of each request", Value: 10 * time.Second},
+		},
+		Action: func(c *cli.Context) error {
+			checks := runDoctor(c)
+
+			out := output{
+				Data:    checks,
+				Columns: []string{"CHECK", "STATUS", "DETAIL", "HINT"},
+				Rows:    [][]string{},
+			}
+			var text strings.Builder
+			failed := false
+			for _, ch := range checks {
+				failed = failed || ch.Status == checkFail
+				out.Rows = append(out.Rows, []string{ch.Name, ch.Status, ch.Detail, ch.Hint})
+				line := fmt.Sprintf("[%4s] %-11s %s", strings.ToUpper(ch.Status), ch.Name, ch.Detail)
+				text.WriteString(strings.TrimRight(line, " ") + "\n")
+				if ch.Hint != "" {
+					fmt.Fprintf(&text, "       hint: %s\n", ch.Hint)
+				}
+			}
+			out.Text = strings.TrimSuffix(text.String(), "\n")
+			if err := printOutput(c, out); err != nil {
+				return err
+			}
+			if failed {
+				return cli.Exit("", 1)
+			}
+			return nil
+		},
+	}
+}
+
+// runDoctor runs the checks in order. Once one fails, the checks that
+// depend on it are skipped.
+func runDoctor(c *cli.Context) []doctorCheck {
+	var checks []doctorCheck
+	add := func(name, status, detail, hint string) {
+		checks = append(checks, doctorCheck{Name: name, Status: status, Detail: detail, Hint: hint})
+	}
+	skip := func(names ...string) []doctorCheck {
+		for _, name := range names {
+			add(name, checkSkip, "", "")
+		}
+		return checks
+	}
+
+	// Credentials.
+	path, err := configPath(c)
+	var cfg *config
+	if err == nil {
+		cfg, err = loadConfig(path)
+	}
+	var url, key string
+	if err == nil {
+		url, key, err = resolveConnection(c.String("url"), c.String("key"), c.String("profile"), cfg, storedKeyLookup(c))
+	}
+	if err != nil {
+		add("config", checkFail, err.Error(), "pass --url and --key, or set up a profile with dokploy profile add and dokploy login")
+		return skip("url", "dns", "connect", "api-key", "version", "permissions")
+	}
+	add("config", checkOK, fmt.Sprintf("%s, key %s", url, maskKey(key)), "")
+
+	// URL.
+	u, err := neturl.Parse(url)
+	switch {
+	case err != nil:
+		add("url", checkFail, err.Error(), "use the base URL of Dokploy, such as https://dokploy.example.com")
+		return skip("dns", "connect", "api-key", "version", "permissions")
+	case u.Scheme != "http" && u.Scheme != "https" || u.Host == "":
+		add("url", checkFail, fmt.Sprintf("%q is not an http(s) URL", url), "use the base URL of Dokploy, such as https://dokploy.example.com")
+		return skip("dns", "connect", "api-key", "version", "permissions")
+	case u.Scheme == "http" && !isLoopback(u.Hostname()):
+		add("url", checkWarn, url, "the API key is sent unencrypted over http; use https")
+	default:
+		add("url", checkOK, url, "")
+	}
+
+	// DNS.
+	host := u.Hostname()
+	if net.ParseIP(host) != nil {
+		add("dns", checkSkip, host+" is an IP address", "")
+	} else {
+		addrs, err := net.DefaultResolver.LookupHost(c.Context, host)
+		if err != nil {
+			add("dns", checkFail, err.Error(), "check the host name in --url and your DNS settings")
+			return skip("connect", "api-key", "version", "permissions")
+		}
+		add("dns", checkOK, fmt.Sprintf("%s resolves to %s", host, strings.Join(addrs, ", ")), "")
+	}
+
+	// Connection, TLS and API key, all from a single user.get call.
+	client, err := dokploy.NewClient(url, key,
+		dokploy.WithRetryPolicy(dokploy.RetryPolicy{}),
+		dokploy.WithTimeout(c.Duration("timeout")),
+		dokploy.WithUserAgent("dokploy-cli/"+version),
+	)
+	if err != nil {
+		add("connect", checkFail, err.Error(), "")
+		return skip("api-key", "version", "permissions")
+	}
+	user, err := dokploy.GetCurrentUser(c.Context, client)
+	var (
+		apiErr    *dokploy.APIError
+		syntaxErr *json.SyntaxError
+	)
+	switch {
+	case errors.As(err, &syntaxErr):
+		add("connect", checkOK, "server answered", "")
+		add("api-key", checkFail, "the response is not JSON: "+err.Error(), "check that --url is the base URL of Dokploy and not of a proxy or another app")
+		return skip("version", "permissions")
+	case err == nil || errors.As(err, &apiErr):
+		detail := "server answered"
+		if u.Scheme == "https" {
+			detail += " over TLS with a valid certificate"
+		}
+		add("connect", checkOK, detail, "")
+	case isTLSError(err):
+		add("connect", checkFail, err.Error(), "the TLS certificate is not trusted: check that --url uses the certificate's host name, or install the CA certificate")
+		return skip("api-key", "version", "permissions")
+	default:
+		add("connect", checkFail, err.Error(), "check that --url is right, Dokploy is running and no firewall or proxy blocks it")
+		return skip("api-key", "version", "permissions")
+	}
+	switch {
+	case err == nil:
+		add("api-key", checkOK, "authenticated as "+user.String(), "")
+	case dokploy.IsUnauthorized(err):
+		add("api-key", checkFail, err.Error(), "the key is invalid or revoked: create one in Dokploy under Profile > API/CLI, then run dokploy login")
+		return skip("version", "permissions")
+	default:
+		add("api-key", checkFail, err.Error(), "check that --url is the base URL of Dokploy and not of a proxy or another app")
+		return skip("version", "permissions")
+	}
+
+	// Server version.
+	if v, err := dokploy.GetServerVersion(c.Context, client); err != nil {
+		add("version", checkWarn, err.Error(), "the key may lack access to server settings; other commands are unaffected")
+	} else {
+		add("version", checkOK, "Dokploy "+v, "")
+	}
+
+	// Permissions.
+	projects, err := dokploy.ListProjects(c.Context, client)
+	switch {
+	case err != nil:
+		add("permissions", checkFail, err.Error(), "the key cannot list projects; ask an owner or admin to grant access")
+	default:
+		detail := fmt.Sprintf("can list projects (%d visible)", len(projects))
+		if perms := user.GrantedPermissions(); len(perms) > 0 {
+			detail += "; " + strings.Join(perms, ", ")
+		}
+		add("permissions", checkOK, detail, "")
+	}
+	return checks
+}
+
+func whoamiCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "whoami",
+		Usage: "Print the Dokploy user the API key belongs to",
+		Action: func(c *cli.Context) error {
+			client, err := newClientFromCtx(c)
+			if err != nil {
+				return err
+			}
+			user, err := dokploy.GetCurrentUser(c.Context, client)
+			if err != nil {
+				return err
+			}
+			return printOutput(c, output{Data: user, IDs: []string{user.ID}, Text: user.String()})
+		},
+	}
+}
+
+// maskKey shows only the end of an API key.
+func maskKey(key string) string {
+	if len(key) <= 8 {
+		return "****"
+	}
+	return "****" + key[len(key)-4:]
+}
+
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// isTLSError reports whether err is a failure to verify the server's
+// certificate or to speak TLS with it.
+func isTLSError(err error) bool {
+	var (
+		verifyErr   *tls.CertificateVerificationError
+		unknownErr  x509.UnknownAuthorityError
+		hostnameErr x509.HostnameError
+		invalidErr  x509.CertificateInvalidError
+		recordErr   tls.RecordHeaderError
+	)
+	return errors.As(err, &verifyErr) || errors.As(err, &unknownErr) || errors.As(err, &hostnameErr) ||
+		errors.As(err, &invalidErr) || errors.As(err, &recordErr)
+}
@@ -0,0 +1,234 @@
+package dokploy
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+)
+
+// Application create: POST /api/application.create
+// Application get: GET /api/application.one?applicationId=...
+// Application update: POST /api/application.update
+// Application build type: POST /api/application.saveBuildType
+// Application docker source: POST /api/application.saveDockerProvider
+// Application git source: POST /api/application.saveGitProdiver
+// Application env: POST /api/application.saveEnvironment
+// Application lifecycle: POST /api/application.{deploy,redeploy,stop,start,delete}
+
+// Build types accepted by application.saveBuildType.
+var ApplicationBuildTypes = []string{
+	"dockerfile",
+	"nixpacks",
+	"heroku_buildpacks",
+	"paketo_buildpacks",
+	"railpack",
+	"static",
+}
+
+// Application is a Dokploy application as returned by application.one.
+type Application struct {
+	ApplicationID     string  `json:"applicationId"`
+	Name              string  `json:"name"`
+	AppName           string  `json:"appName"`
+	Description       *string `json:"description"`
+	EnvironmentID     string  `json:"environmentId"`
+	ApplicationStatus string  `json:"applicationStatus"`
+	SourceType        string  `json:"sourceType"`
+	BuildType         string  `json:"buildType"`
+	Env               *string `json:"env"`
+
+	Dockerfile        *string `json:"dockerfile"`
+	DockerContextPath *string `json:"dockerContextPath"`
+	DockerBuildStage  *string `json:"dockerBuildStage"`
+	PublishDirectory  *string `json:"publishDirectory"`
+
+	DockerImage *string `json:"dockerImage"`
+	RegistryURL *string `json:"registryUrl"`
+	Username    *string `json:"username"`
+
+	CustomGitURL       *string `json:"customGitUrl"`
+	CustomGitBranch    *string `json:"customGitBranch"`
+	CustomGitBuildPath *string `json:"customGitBuildPath"`
+	CustomGitSSHKeyID  *string `json:"customGitSSHKeyId"`
+
+	CreatedAt string `json:"createdAt"`
+}
+
+// ApplicationBuild configures how Dokploy builds an application. Only the
+// fields relevant to BuildType are used by Dokploy.
+type ApplicationBuild struct {
+	BuildType         string
+	Dockerfile        string
+	DockerContextPath string
+	DockerBuildStage  string
+	PublishDirectory  string
+}
+
+// DockerSource makes an application run a prebuilt image.
+type DockerSource struct {
+	Image       string
+	RegistryURL string
+	Username    string
+	Password    string
+}
+
+// GitSource makes an application build from a Git repository.
+type GitSource struct {
+	URL       string
+	Branch    string
+	BuildPath string
+	SSHKeyID  string
+}
+
+type applicationCreateResponse struct {
+	ApplicationID string `json:"applicationId"`
+}
+
+// CreateApplication calls POST /api/application.create and returns the new
+// application ID.
+func CreateApplication(ctx context.Context, client *Client, name, description, environmentID string) (string, error) {
+	payload := map[string]any{
+		"name":          name,
+		"environmentId": environmentID,
+	}
+	if description != "" {
+		payload["description"] = description
+	}
+	var resp applicationCreateResponse
+	if err := client.do(ctx, http.MethodPost, "/api/application.create", payload, &resp); err != nil {
+		return "", err
+	}
+	return resp.ApplicationID, nil
+}
+
+// GetApplication calls GET /api/application.one.
+func GetApplication(ctx context.Context, client *Client, id string) (*Application, error) {
+	if id == "" {
+		return nil, errors.New("application id is required")
+	}
+	q := url.Values{}
+	q.Set("applicationId", id)
+	var out Application
+	if err := client.do(ctx, http.MethodGet, "/api/application.one?"+q.Encode(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// UpdateApplication calls POST /api/application.update, changing only the
+// fields that are non-empty.
+func UpdateApplication(ctx context.Context, client *Client, id, name, description string) error {
+	payload := map[string]any{
+		"applicationId": id,
+	}
+	if name != "" {
+		payload["name"] = name
+	}
+	if description != "" {
+		payload["description"] = description
+	}
+	return client.do(ctx, http.MethodPost, "/api/application.update", payload, nil)
+}
+
+// SaveApplicationBuildType calls POST /api/application.saveBuildType.
+func SaveApplicationBuildType(ctx context.Context, client *Client, id string, build ApplicationBuild) error {
+	payload := map[string]any{
+		"applicationId":     id,
+		"buildType":         build.BuildType,
+		"dockerfile":        nullable(build.Dockerfile),
+		"dockerContextPath": nullable(build.DockerContextPath),
+		"dockerBuildStage":  nullable(build.DockerBuildStage),
+		"publishDirectory":  nullable(build.PublishDirectory),
+	}
+	return client.do(ctx, http.MethodPost, "/api/application.saveBuildType", payload, nil)
+}
+
+// SaveApplicationDockerSource calls POST /api/application.saveDockerProvider.
+func SaveApplicationDockerSource(ctx context.Context, client *Client, id string, src DockerSource) error {
+	payload := map[string]any{
+		"applicationId": id,
+		"dockerImage":   src.Image,
+		"registryUrl":   nullable(src.RegistryURL),
+		"username":      nullable(src.Username),
+		"password":      nullable(src.Password),
+	}
+	return client.do(ctx, http.MethodPost, "/api/application.saveDockerProvider", payload, nil)
+}
+
+// SaveApplicationGitSource calls POST /api/application.saveGitProdiver (the
+// endpoint name is misspelled in Dokploy's API).
+func SaveApplicationGitSource(ctx context.Context, client *Client, id string, src GitSource) error {
+	buildPath := src.BuildPath
+	if buildPath == "" {
+		buildPath = "/"
+	}
+	payload := map[string]any{
+		"applicationId":      id,
+		"customGitUrl":       src.URL,
+		"customGitBranch":    src.Branch,
+		"customGitBuildPath": buildPath,
+		"customGitSSHKeyId":  nullable(src.SSHKeyID),
+		"watchPaths":         []string{},
+		"enableSubmodules":   false,
+	}
+	return client.do(ctx, http.MethodPost, "/api/application.saveGitProdiver", payload, nil)
+}
+
+// SaveApplicationEnv calls POST /api/application.saveEnvironment, replacing
+// the application's env vars.
+func SaveApplicationEnv(ctx context.Context, client *Client, id string, envVars map[string]string) error {
+	return SaveApplicationEnvDocument(ctx, client, id, EnvFromMap(envVars))
+}
+
+// SaveApplicationEnvDocument is SaveApplicationEnv with env sent as
+// written, keeping its order, comments and repeated keys.
+func SaveApplicationEnvDocument(ctx context.Context, client *Client, id string, env *EnvDocument) error {
+	payload := map[string]any{
+		"applicationId": id,
+		"env":           env.String(),
+	}
+	return client.do(ctx, http.MethodPost, "/api/application.saveEnvironment", payload, nil)
+}
+
+// DeployApplication calls POST /api/application.deploy.
+func DeployApplication(ctx context.Context, client *Client, id string) error {
+	return applicationAction(ctx, client, "deploy", id)
+}
+
+// RedeployApplication calls POST /api/application.redeploy, rebuilding the
+// application from its current source.
+func RedeployApplication(ctx context.Context, client *Client, id string) error {
+	return applicationAction(ctx, client, "redeploy", id)
+}
+
+// StopApplication calls POST /api/application.stop.
+func StopApplication(ctx context.Context, client *Client, id string) error {
+	return applicationAction(ctx, client, "stop", id)
+}
+
+// StartApplication calls POST /api/application.start.
+func StartApplication(ctx context.Context, client *Client, id string) error {
+	return applicationAction(ctx, client, "start", id)
+}
+
+// DeleteApplication calls POST /api/application.delete.
+func DeleteApplication(ctx context.Context, client *Client, id string) error {
+	return applicationAction(ctx, client, "delete", id)
+}
+
+func applicationAction(ctx context.Context, client *Client, action, id string) error {
+	payload := map[string]any{
+		"applicationId": id,
+	}
+	return client.do(ctx, http.MethodPost, "/api/application."+action, payload, nil)
+}
+
+// nullable returns nil for an empty string so that optional fields are sent
+// as JSON null, which Dokploy expects for "not set".
+func nullable(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
@@ -0,0 +1,192 @@
+package dokploy
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCreateApplication_CallsApplicationCreate(t *testing.T) {
+	t.Helper()
+
+	var gotPath string
+	var gotBody map[string]any
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		if err := json.NewDecoder(r.Body).Decode(&gotBody); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"applicationId": "app-123", "name": "api"})
+	}))
+	defer ts.Close()
+
+	client, err := NewClient(ts.URL, "key")
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+
+	id, err := CreateApplication(context.Background(), client, "api", "", "env-1")
+	if err != nil {
+		t.Fatalf("CreateApplication error: %v", err)
+	}
+	if gotPath != "/api/application.create" {
+		t.Errorf("path = %q, want %q", gotPath, "/api/application.create")
+	}
+	if gotBody["name"] != "api" || gotBody["environmentId"] != "env-1" {
+		t.Errorf("body = %v, want name api and environmentId env-1", gotBody)
+	}
+	if _, ok := gotBody["description"]; ok {
+		t.Errorf("description should be omitted when empty, got %v", gotBody["description"])
+	}
+	if id != "app-123" {
+		t.Errorf("id = %q, want %q", id, "app-123")
+	}
+}
+
+func TestGetApplication_CallsApplicationOne(t *testing.T) {
+	t.Helper()
+
+	var gotQuery string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/application.one" || r.Method != http.MethodGet {
+			t.Errorf("request = %s %s, want GET /api/application.one", r.Method, r.URL.Path)
+		}
+		gotQuery = r.URL.Query().Get("applicationId")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"applicationId": "app-1",
+			"name":          "api",
+			"sourceType":    "docker",
+			"buildType":     "dockerfile",
+			"dockerImage":   "nginx:alpine",
+		})
+	}))
+	defer ts.Close()
+
+	client, err := NewClient(ts.URL, "key")
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+
+	app, err := GetApplication(context.Background(), client, "app-1")
+	if err != nil {
+		t.Fatalf("GetApplication error: %v", err)
+	}
+	if gotQuery != "app-1" {
+		t.Errorf("applicationId = %q, want %q", gotQuery, "app-1")
+	}
+	if app.SourceType != "docker" || app.DockerImage == nil || *app.DockerImage != "nginx:alpine" {
+		t.Errorf("app = %+v, want docker source nginx:alpine", app)
+	}
+}
+
+func TestSaveApplicationSettings_Payloads(t *testing.T) {
+	t.Helper()
+
+	bodies := map[string]map[string]any{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		bodies[r.URL.Path] = body
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	client, err := NewClient(ts.URL, "key")
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	ctx := context.Background()
+
+	if err := SaveApplicationBuildType(ctx, client, "app-1", ApplicationBuild{BuildType: "dockerfile", Dockerfile: "Dockerfile"}); err != nil {
+		t.Fatalf("SaveApplicationBuildType error: %v", err)
+	}
+	if err := SaveApplicationDockerSource(ctx, client, "app-1", DockerSource{Image: "ghcr.io/acme/api:1.2"}); err != nil {
+		t.Fatalf("SaveApplicationDockerSource error: %v", err)
+	}
+	if err := SaveApplicationGitSource(ctx, client, "app-1", GitSource{URL: "git@github.com:acme/api.git", Branch: "main", SSHKeyID: "key-1"}); err != nil {
+		t.Fatalf("SaveApplicationGitSource error: %v", err)
+	}
+	env, err := ParseEnvDocument("# api\nPORT=8080\nHOST=0.0.0.0", nil)
+	if err != nil {
+		t.Fatalf("ParseEnvDocument error: %v", err)
+	}
+	if err := SaveApplicationEnvDocument(ctx, client, "app-1", env); err != nil {
+		t.Fatalf("SaveApplicationEnvDocument error: %v", err)
+	}
+
+	build := bodies["/api/application.saveBuildType"]
+	if build["buildType"] != "dockerfile" || build["dockerfile"] != "Dockerfile" {
+		t.Errorf("saveBuildType body = %v", build)
+	}
+	if v, ok := build["dockerBuildStage"]; !ok || v != nil {
+		t.Errorf("dockerBuildStage = %v, want null", v)
+	}
+
+	docker := bodies["/api/application.saveDockerProvider"]
+	if docker["dockerImage"] != "ghcr.io/acme/api:1.2" || docker["applicationId"] != "app-1" {
+		t.Errorf("saveDockerProvider body = %v", docker)
+	}
+
+	git := bodies["/api/application.saveGitProdiver"]
+	if git["customGitUrl"] != "git@github.com:acme/api.git" || git["customGitBranch"] != "main" {
+		t.Errorf("saveGitProdiver body = %v", git)
+	}
+	if git["customGitBuildPath"] != "/" {
+		t.Errorf("customGitBuildPath = %v, want /", git["customGitBuildPath"])
+	}
+	if git["customGitSSHKeyId"] != "key-1" {
+		t.Errorf("customGitSSHKeyId = %v, want key-1", git["customGitSSHKeyId"])
+	}
+
+	// The document is sent as written, not sorted.
+	if got, want := bodies["/api/application.saveEnvironment"]["env"], "# api\nPORT=8080\nHOST=0.0.0.0"; got != want {
+		t.Errorf("saveEnvironment env = %q, want %q", got, want)
+	}
+}
+
+func TestApplicationLifecycle_CallsEndpoints(t *testing.T) {
+	t.Helper()
+
+	var gotPaths []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body["applicationId"] != "app-1" {
+			t.Errorf("%s applicationId = %v, want app-1", r.URL.Path, body["applicationId"])
+		}
+		gotPaths = append(gotPaths, r.URL.Path)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	client, err := NewClient(ts.URL, "key")
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	ctx := context.Background()
+
+	for _, fn := range []func(context.Context, *Client, string) error{
+		DeployApplication, RedeployApplication, StopApplication, StartApplication, DeleteApplication,
+	} {
+		if err := fn(ctx, client, "app-1"); err != nil {
+			t.Fatalf("lifecycle call error: %v", err)
+		}
+	}
+
+	want := []string{"/api/application.deploy", "/api/application.redeploy", "/api/application.stop", "/api/application.start", "/api/application.delete"}
+	if len(gotPaths) != len(want) {
+		t.Fatalf("paths = %v, want %v", gotPaths, want)
+	}
+	for i := range want {
+		if gotPaths[i] != want[i] {
+			t.Errorf("paths[%d] = %q, want %q", i, gotPaths[i], want[i])
+		}
+	}
+}
@@ -1,9 +1,13 @@
 package dokploy
 
 import (
+	"bytes"
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"errors"
+	"io"
+	"log"
 	"net/http"
 	"strings"
 	"time"
@@ -14,62 +18,147 @@ type Client struct {
 	baseURL    string
 	apiKey     string
 	httpClient *http.Client
+	retry      RetryPolicy
+	userAgent  string
+	hooks      []func(*http.Request)
+	logger     *log.Logger
+
+	// Set by options and combined into httpClient by NewClient.
+	baseHTTPClient *http.Client
+	timeout        *time.Duration
+	transport      http.RoundTripper
+	tlsConfig      *tls.Config
 }
 
-func NewClient(baseURL, apiKey string) (*Client, error) {
+// ClientOption configures a Client created by NewClient.
+type ClientOption func(*Client)
+
+// NewClient returns a client for the Dokploy instance at baseURL that
+// authenticates with apiKey. Without options it uses a 30-second timeout
+// and DefaultRetryPolicy.
+func NewClient(baseURL, apiKey string, opts ...ClientOption) (*Client, error) {
 	baseURL = strings.TrimSpace(baseURL)
 	apiKey = strings.TrimSpace(apiKey)
 	if baseURL == "" || apiKey == "" {
 		return nil, errors.New("url and key are required")
 	}
 	baseURL = strings.TrimRight(baseURL, "/")
-	return &Client{
+	c := &Client{
 		baseURL: baseURL,
 		apiKey:  apiKey,
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
-	}, nil
+		retry:   DefaultRetryPolicy(),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	hc := &http.Client{Timeout: 30 * time.Second}
+	if c.baseHTTPClient != nil {
+		cp := *c.baseHTTPClient
+		hc = &cp
+	}
+	if c.timeout != nil {
+		hc.Timeout = *c.timeout
+	}
+	if c.transport != nil {
+		hc.Transport = c.transport
+	}
+	if c.tlsConfig != nil {
+		rt := hc.Transport
+		if rt == nil {
+			rt = http.DefaultTransport
+		}
+		t, ok := rt.(*http.Transport)
+		if !ok {
+			return nil, errors.New("WithTLSConfig requires an *http.Transport")
+		}
+		t = t.Clone()
+		t.TLSClientConfig = c.tlsConfig
+		hc.Transport = t
+	}
+	c.httpClient = hc
+	return c, nil
 }
 
 func (c *Client) do(ctx context.Context, method, path string, body any, out any) error {
-	url := c.baseURL + path
-
-	var reqBody *strings.Reader
+	var payload []byte
 	if body != nil {
 		b, err := json.Marshal(body)
 		if err != nil {
 			return err
 		}
-		reqBody = strings.NewReader(string(b))
-	} else {
-		reqBody = strings.NewReader("")
+		payload = b
+	}
+
+	attempts := c.retry.attempts(method)
+	for attempt := 1; ; attempt++ {
+		start := time.Now()
+		resp, err := c.send(ctx, method, path, body != nil, payload)
+		if err != nil {
+			c.logf("%s %s: %v (%s)", method, path, err, time.Since(start).Round(time.Millisecond))
+			if attempt >= attempts || ctx.Err() != nil {
+				return err
+			}
+			wait := c.retry.delay(attempt, "")
+			c.logf("retrying %s %s in %s (attempt %d of %d)", method, path, wait, attempt+1, attempts)
+			if err := sleepCtx(ctx, wait); err != nil {
+				return err
+			}
+			continue
+		}
+		c.logf("%s %s: %s (%s)", method, path, resp.Status, time.Since(start).Round(time.Millisecond))
+
+		if attempt < attempts && c.retry.retryable(resp.StatusCode) {
+			wait := c.retry.delay(attempt, resp.Header.Get("Retry-After"))
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+			c.logf("retrying %s %s in %s (attempt %d of %d)", method, path, wait, attempt+1, attempts)
+			if err := sleepCtx(ctx, wait); err != nil {
+				return err
+			}
+			continue
+		}
+
+		defer resp.Body.Close()
+		if resp.StatusCode >= 400 {
+			return newAPIError(method, path, resp)
+		}
+
+		if out != nil {
+			dec := json.NewDecoder(resp.Body)
+			if err := dec.Decode(out); err != nil {
+				return err
+			}
+		}
+		return nil
 	}
+}
+
+// send performs a single attempt of a request. The body is rebuilt from
+// payload each time so that the request can be replayed.
+func (c *Client) send(ctx context.Context, method, path string, hasBody bool, payload []byte) (*http.Response, error) {
+	url := c.baseURL + path
 
-	req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(payload))
 	if err != nil {
-		return err
+		return nil, err
 	}
-	if body != nil {
+	if hasBody {
 		req.Header.Set("Content-Type", "application/json")
 	}
 	req.Header.Set("x-api-key", c.apiKey)
-
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return err
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode >= 400 {
-		return errors.New(resp.Status)
+	for _, hook := range c.hooks {
+		hook(req)
 	}
 
-	if out != nil {
-		dec := json.NewDecoder(resp.Body)
-		if err := dec.Decode(out); err != nil {
-			return err
-		}
+	return c.httpClient.Do(req)
+}
+
+func (c *Client) logf(format string, args ...any) {
+	if c.logger != nil {
+		c.logger.Printf(format, args...)
 	}
-	return nil
 }
@@ -2,10 +2,12 @@ package dokploy
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
-	"sort"
+	"strconv"
 	"strings"
 )
 
@@ -15,11 +17,19 @@ type composeCreateUpdateResponse struct {
 }
 
 // GetCompose retrieves a compose app using the official Dokploy
-// GET /api/compose.one?composeId=... endpoint. Lookup by name is not
-// supported by that endpoint, so an id is required.
+// GET /api/compose.one?composeId=... endpoint. That endpoint needs an id, so
+// when only name is given the compose app is first found by name across all
+// projects with FindCompose. GetComposeByID returns the same data typed.
 func GetCompose(ctx context.Context, client *Client, id, name string) (map[string]any, error) {
 	if id == "" {
-		return nil, fmt.Errorf("Dokploy compose.one API requires --id; lookup by --name is not supported")
+		if name == "" {
+			return nil, errors.New("compose id or name is required")
+		}
+		found, err := FindCompose(ctx, client, "", "", name)
+		if err != nil {
+			return nil, err
+		}
+		id = found.ComposeID
 	}
 	q := url.Values{}
 	q.Set("composeId", id)
@@ -31,17 +41,170 @@ func GetCompose(ctx context.Context, client *Client, id, name string) (map[strin
 	return out, nil
 }
 
+// Compose is a Dokploy compose app as returned by compose.one.
+type Compose struct {
+	ComposeID     string  `json:"composeId"`
+	Name          string  `json:"name"`
+	AppName       string  `json:"appName"`
+	Description   *string `json:"description"`
+	EnvironmentID string  `json:"environmentId"`
+	ComposeStatus string  `json:"composeStatus"`
+	ComposeType   string  `json:"composeType"`
+	SourceType    string  `json:"sourceType"`
+	ComposeFile   string  `json:"composeFile"`
+	ComposePath   string  `json:"composePath"`
+	Env           *string `json:"env"`
+	Command       string  `json:"command"`
+
+	Repository      *string `json:"repository"`
+	Owner           *string `json:"owner"`
+	Branch          *string `json:"branch"`
+	CustomGitURL    *string `json:"customGitUrl"`
+	CustomGitBranch *string `json:"customGitBranch"`
+
+	Domains     []Domain     `json:"domains"`
+	Mounts      []Mount      `json:"mounts"`
+	Deployments []Deployment `json:"deployments"`
+
+	CreatedAt string `json:"createdAt"`
+
+	// Raw is the compose.one response as received, for fields not modelled
+	// above.
+	Raw json.RawMessage `json:"-"`
+}
+
+// Mount is a volume, bind mount or file mounted into a service.
+type Mount struct {
+	MountID     string  `json:"mountId"`
+	Type        string  `json:"type"`
+	HostPath    *string `json:"hostPath"`
+	VolumeName  *string `json:"volumeName"`
+	FilePath    *string `json:"filePath"`
+	Content     *string `json:"content"`
+	MountPath   string  `json:"mountPath"`
+	ServiceType string  `json:"serviceType"`
+}
+
+// GetComposeByID calls GET /api/compose.one and returns the compose app with
+// its domains, mounts and deployments.
+func GetComposeByID(ctx context.Context, client *Client, id string) (*Compose, error) {
+	if id == "" {
+		return nil, errors.New("compose id is required")
+	}
+	q := url.Values{}
+	q.Set("composeId", id)
+	var raw json.RawMessage
+	if err := client.do(ctx, http.MethodGet, "/api/compose.one?"+q.Encode(), nil, &raw); err != nil {
+		return nil, err
+	}
+	var out Compose
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return nil, err
+	}
+	out.Raw = raw
+	return &out, nil
+}
+
+// ComposeSummary is a compose app found by ListComposes, with the project
+// and environment it belongs to.
+type ComposeSummary struct {
+	ProjectCompose
+	ProjectID       string `json:"projectId"`
+	ProjectName     string `json:"projectName"`
+	EnvironmentID   string `json:"environmentId"`
+	EnvironmentName string `json:"environmentName"`
+}
+
+// ErrComposeNotFound is returned by FindCompose when no compose app matches.
+var ErrComposeNotFound = errors.New("compose not found")
+
+// AmbiguousComposeError is returned by FindCompose when more than one
+// compose app has the requested name.
+type AmbiguousComposeError struct {
+	Name    string
+	Matches []ComposeSummary
+}
+
+func (e *AmbiguousComposeError) Error() string {
+	where := make([]string, len(e.Matches))
+	for i, m := range e.Matches {
+		where[i] = fmt.Sprintf("%s/%s (%s)", m.ProjectName, m.EnvironmentName, m.ComposeID)
+	}
+	return fmt.Sprintf("compose name %q is ambiguous, it matches %d compose apps: %s; narrow it down by project and environment or use the compose ID",
+		e.Name, len(e.Matches), strings.Join(where, ", "))
+}
+
+// ListComposes returns the compose apps of every project, using the data
+// returned from ListProjects. project and environment, if non-empty, keep
+// only the compose apps of the project and environment with that name or ID.
+func ListComposes(ctx context.Context, client *Client, project, environment string) ([]ComposeSummary, error) {
+	projects, err := ListProjects(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	out := []ComposeSummary{}
+	for _, p := range projects {
+		if project != "" && p.Name != project && p.ProjectID != project {
+			continue
+		}
+		for _, env := range p.Environments {
+			if environment != "" && env.Name != environment && env.EnvironmentID != environment {
+				continue
+			}
+			for _, cmp := range env.Compose {
+				out = append(out, ComposeSummary{
+					ProjectCompose:  cmp,
+					ProjectID:       p.ProjectID,
+					ProjectName:     p.Name,
+					EnvironmentID:   env.EnvironmentID,
+					EnvironmentName: env.Name,
+				})
+			}
+		}
+	}
+	return out, nil
+}
+
+// FindCompose finds the compose app called name, within project and
+// environment when they are non-empty (see ListComposes). It returns
+// ErrComposeNotFound if there is none and an *AmbiguousComposeError if the
+// name is not unique.
+func FindCompose(ctx context.Context, client *Client, project, environment, name string) (*ComposeSummary, error) {
+	composes, err := ListComposes(ctx, client, project, environment)
+	if err != nil {
+		return nil, err
+	}
+	var matches []ComposeSummary
+	for _, cmp := range composes {
+		if cmp.Name == name {
+			matches = append(matches, cmp)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("compose %q: %w", name, ErrComposeNotFound)
+	case 1:
+		return &matches[0], nil
+	}
+	return nil, &AmbiguousComposeError{Name: name, Matches: matches}
+}
+
 // CreateOrUpdateCompose maps to Dokploy's compose.create and compose.update APIs.
 // If id is empty, it calls POST /api/compose.create; otherwise it calls
-// POST /api/compose.update with composeId.
+// POST /api/compose.update with composeId. envVars are sent sorted by key;
+// use CreateOrUpdateComposeWithEnv to keep their order and comments.
 func CreateOrUpdateCompose(ctx context.Context, client *Client, id, name, environmentID, composeContent string, envVars map[string]string) (string, error) {
-	// Dokploy expects env as a single string; join KEY=VALUE pairs.
-	var envLines []string
-	for k, v := range envVars {
-		envLines = append(envLines, fmt.Sprintf("%s=%s", k, v))
+	return CreateOrUpdateComposeWithEnv(ctx, client, id, name, environmentID, composeContent, EnvFromMap(envVars))
+}
+
+// CreateOrUpdateComposeWithEnv is CreateOrUpdateCompose with env sent as
+// written, see EnvDocument. A nil or empty env leaves the compose app's env
+// unchanged.
+func CreateOrUpdateComposeWithEnv(ctx context.Context, client *Client, id, name, environmentID, composeContent string, env *EnvDocument) (string, error) {
+	var envString string
+	if env != nil {
+		envString = env.String()
 	}
-	sort.Strings(envLines)
-	envString := strings.Join(envLines, "\n")
 
 	if id == "" {
 		payload := map[string]any{
@@ -94,6 +257,146 @@ func CreateOrUpdateCompose(ctx context.Context, client *Client, id, name, enviro
 	return id, nil
 }
 
+// Compose source types accepted by compose.update. "raw" uses the compose
+// file stored in Dokploy; the others clone a repository on each deploy.
+var ComposeSourceTypes = []string{"raw", "git", ProviderGitHub, ProviderGitLab, ProviderBitbucket, ProviderGitea}
+
+// DefaultComposePath is the compose file Dokploy looks for in a repository.
+const DefaultComposePath = "./docker-compose.yml"
+
+// ComposeSource tells Dokploy where a compose app's compose file comes from.
+type ComposeSource struct {
+	Type        string // one of ComposeSourceTypes
+	Branch      string
+	ComposePath string // file in the repository; DefaultComposePath if empty
+
+	// For "git": the repository URL, and an SSH key stored in Dokploy for
+	// private repositories.
+	URL      string
+	SSHKeyID string
+
+	// For providers: the provider ID (see GitProvider.ProviderID) and the
+	// repository as "owner/repo" ("group/subgroup/repo" on GitLab).
+	ProviderID string
+	Repository string
+	// GitLabProjectID is the numeric ID of a GitLab project, see
+	// ListProviderRepositories.
+	GitLabProjectID string
+}
+
+// SaveComposeSource calls POST /api/compose.update with the source fields
+// for src.Type, leaving the compose app's other settings unchanged.
+func SaveComposeSource(ctx context.Context, client *Client, id string, src ComposeSource) error {
+	payload := map[string]any{
+		"composeId":  id,
+		"sourceType": src.Type,
+	}
+	if src.Type != "raw" {
+		if src.Branch == "" {
+			return fmt.Errorf("%s source: branch is required", src.Type)
+		}
+		composePath := src.ComposePath
+		if composePath == "" {
+			composePath = DefaultComposePath
+		}
+		payload["composePath"] = composePath
+	}
+
+	switch src.Type {
+	case "raw":
+	case "git":
+		if src.URL == "" {
+			return errors.New("git source: repository URL is required")
+		}
+		payload["customGitUrl"] = src.URL
+		payload["customGitBranch"] = src.Branch
+		payload["customGitSSHKeyId"] = nullable(src.SSHKeyID)
+	case ProviderGitHub, ProviderGitLab, ProviderBitbucket, ProviderGitea:
+		owner, repo := splitRepository(src.Repository)
+		switch {
+		case src.ProviderID == "":
+			return fmt.Errorf("%s source: provider ID is required", src.Type)
+		case owner == "" || repo == "":
+			return fmt.Errorf("%s source: repository %q must be owner/name", src.Type, src.Repository)
+		}
+		switch src.Type {
+		case ProviderGitHub:
+			payload["githubId"] = src.ProviderID
+			payload["owner"] = owner
+			payload["repository"] = repo
+			payload["branch"] = src.Branch
+		case ProviderGitLab:
+			payload["gitlabId"] = src.ProviderID
+			payload["gitlabOwner"] = owner
+			payload["gitlabRepository"] = repo
+			payload["gitlabPathNamespace"] = owner + "/" + repo
+			payload["gitlabBranch"] = src.Branch
+			if src.GitLabProjectID != "" {
+				projectID, err := strconv.Atoi(src.GitLabProjectID)
+				if err != nil {
+					return fmt.Errorf("gitlab source: invalid project ID %q", src.GitLabProjectID)
+				}
+				payload["gitlabProjectId"] = projectID
+			}
+		case ProviderBitbucket:
+			payload["bitbucketId"] = src.ProviderID
+			payload["bitbucketOwner"] = owner
+			payload["bitbucketRepository"] = repo
+			payload["bitbucketBranch"] = src.Branch
+		case ProviderGitea:
+			payload["giteaId"] = src.ProviderID
+			payload["giteaOwner"] = owner
+			payload["giteaRepository"] = repo
+			payload["giteaBranch"] = src.Branch
+		}
+	default:
+		return fmt.Errorf("unknown compose source type %q (want one of %s)", src.Type, strings.Join(ComposeSourceTypes, ", "))
+	}
+	return client.do(ctx, http.MethodPost, "/api/compose.update", payload, nil)
+}
+
+// GetComposeEnv calls GET /api/compose.one and parses the compose app's env
+// vars with ParseEnv. ${VAR} references are returned unexpanded.
+func GetComposeEnv(ctx context.Context, client *Client, id string) (map[string]string, error) {
+	env, err := GetComposeEnvDocument(ctx, client, id)
+	if err != nil {
+		return nil, err
+	}
+	return env.Map(), nil
+}
+
+// GetComposeEnvDocument is GetComposeEnv returning the env as written, see
+// EnvDocument.
+func GetComposeEnvDocument(ctx context.Context, client *Client, id string) (*EnvDocument, error) {
+	cmp, err := GetComposeByID(ctx, client, id)
+	if err != nil {
+		return nil, err
+	}
+	if cmp.Env == nil {
+		return &EnvDocument{}, nil
+	}
+	env, err := ParseEnvDocument(*cmp.Env, nil)
+	if err != nil {
+		return nil, fmt.Errorf("env of compose %s: %w", id, err)
+	}
+	return env, nil
+}
+
+// SaveComposeEnv calls POST /api/compose.update with only the env, replacing
+// the compose app's env vars and leaving its other settings unchanged.
+func SaveComposeEnv(ctx context.Context, client *Client, id string, envVars map[string]string) error {
+	return SaveComposeEnvDocument(ctx, client, id, EnvFromMap(envVars))
+}
+
+// SaveComposeEnvDocument is SaveComposeEnv with env sent as written.
+func SaveComposeEnvDocument(ctx context.Context, client *Client, id string, env *EnvDocument) error {
+	payload := map[string]any{
+		"composeId": id,
+		"env":       env.String(),
+	}
+	return client.do(ctx, http.MethodPost, "/api/compose.update", payload, nil)
+}
+
 // DeleteCompose calls POST /api/compose.delete with configurable deleteVolumes.
 func DeleteCompose(ctx context.Context, client *Client, id string, deleteVolumes bool) error {
 	payload := map[string]any{
@@ -105,8 +408,42 @@ func DeleteCompose(ctx context.Context, client *Client, id string, deleteVolumes
 
 // DeployCompose calls POST /api/compose.deploy with the composeId.
 func DeployCompose(ctx context.Context, client *Client, id string) error {
+	return composeAction(ctx, client, "deploy", id)
+}
+
+// RedeployCompose calls POST /api/compose.redeploy, which queues a
+// deployment that rebuilds the compose app from its current source.
+func RedeployCompose(ctx context.Context, client *Client, id string) error {
+	return composeAction(ctx, client, "redeploy", id)
+}
+
+// StopCompose calls POST /api/compose.stop, stopping the compose app's
+// containers. Dokploy sets its status to idle once they are stopped.
+func StopCompose(ctx context.Context, client *Client, id string) error {
+	return composeAction(ctx, client, "stop", id)
+}
+
+// StartCompose calls POST /api/compose.start, starting the compose app's
+// stopped containers without rebuilding them.
+func StartCompose(ctx context.Context, client *Client, id string) error {
+	return composeAction(ctx, client, "start", id)
+}
+
+// CancelComposeDeployment calls POST /api/compose.cancelDeployment,
+// cancelling the compose app's running deployment.
+func CancelComposeDeployment(ctx context.Context, client *Client, id string) error {
+	return composeAction(ctx, client, "cancelDeployment", id)
+}
+
+// CleanComposeQueues calls POST /api/compose.cleanQueues, dropping the
+// compose app's deployments that are queued but not started.
+func CleanComposeQueues(ctx context.Context, client *Client, id string) error {
+	return composeAction(ctx, client, "cleanQueues", id)
+}
+
+func composeAction(ctx context.Context, client *Client, action, id string) error {
 	payload := map[string]any{
 		"composeId": id,
 	}
-	return client.do(ctx, http.MethodPost, "/api/compose.deploy", payload, nil)
+	return client.do(ctx, http.MethodPost, "/api/compose."+action, payload, nil)
 }
@@ -3,6 +3,7 @@ package dokploy
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -54,6 +55,37 @@ func TestCreateCompose_CallsComposeCreate(t *testing.T) {
 	}
 }
 
+func TestCreateOrUpdateComposeWithEnv_SendsEnvAsWritten(t *testing.T) {
+	t.Helper()
+
+	var gotBody map[string]any
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&gotBody); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"composeId": "cmp-123"})
+	}))
+	defer ts.Close()
+
+	client, err := NewClient(ts.URL, "key")
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+
+	const envText = "# app\nB=2\nA=1 # first\nA=3"
+	env, err := ParseEnvDocument(envText, nil)
+	if err != nil {
+		t.Fatalf("ParseEnvDocument error: %v", err)
+	}
+	if _, err := CreateOrUpdateComposeWithEnv(context.Background(), client, "", "my-compose", "env-1", "services: {}", env); err != nil {
+		t.Fatalf("CreateOrUpdateComposeWithEnv error: %v", err)
+	}
+	if gotBody["env"] != envText {
+		t.Errorf("env = %q, want %q as written", gotBody["env"], envText)
+	}
+}
+
 func TestUpdateCompose_CallsComposeUpdate(t *testing.T) {
 	t.Helper()
 
@@ -151,3 +183,336 @@ func TestDeployCompose_CallsComposeDeploy(t *testing.T) {
 		t.Errorf("composeId = %v, want %v", gotBody["composeId"], "cmp-1")
 	}
 }
+
+func composeProjectsServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	projects := []Project{
+		{
+			ProjectID: "proj-1",
+			Name:      "shop",
+			Environments: []ProjectEnvironment{
+				{EnvironmentID: "env-1", Name: "production", Compose: []ProjectCompose{{ComposeID: "cmp-1", Name: "web"}, {ComposeID: "cmp-2", Name: "worker"}}},
+				{EnvironmentID: "env-2", Name: "staging", Compose: []ProjectCompose{{ComposeID: "cmp-3", Name: "web"}}},
+			},
+		},
+		{
+			ProjectID:    "proj-2",
+			Name:         "blog",
+			Environments: []ProjectEnvironment{{EnvironmentID: "env-3", Name: "production", Compose: []ProjectCompose{{ComposeID: "cmp-4", Name: "ghost"}}}},
+		},
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/project.all", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(projects)
+	})
+	mux.HandleFunc("/api/compose.one", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"composeId": r.URL.Query().Get("composeId")})
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestListComposes_FiltersByProjectAndEnvironment(t *testing.T) {
+	ts := composeProjectsServer(t)
+	defer ts.Close()
+
+	client, err := NewClient(ts.URL, "key")
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+
+	all, err := ListComposes(context.Background(), client, "", "")
+	if err != nil {
+		t.Fatalf("ListComposes error: %v", err)
+	}
+	if len(all) != 4 {
+		t.Errorf("ListComposes() = %d compose apps, want 4", len(all))
+	}
+
+	got, err := ListComposes(context.Background(), client, "shop", "env-2")
+	if err != nil {
+		t.Fatalf("ListComposes error: %v", err)
+	}
+	if len(got) != 1 || got[0].ComposeID != "cmp-3" || got[0].ProjectName != "shop" || got[0].EnvironmentName != "staging" {
+		t.Errorf("ListComposes(shop, env-2) = %+v, want cmp-3 in shop/staging", got)
+	}
+}
+
+func TestFindCompose(t *testing.T) {
+	ts := composeProjectsServer(t)
+	defer ts.Close()
+
+	client, err := NewClient(ts.URL, "key")
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	ctx := context.Background()
+
+	found, err := FindCompose(ctx, client, "shop", "staging", "web")
+	if err != nil {
+		t.Fatalf("FindCompose error: %v", err)
+	}
+	if found.ComposeID != "cmp-3" {
+		t.Errorf("ComposeID = %q, want %q", found.ComposeID, "cmp-3")
+	}
+
+	_, err = FindCompose(ctx, client, "", "", "web")
+	var ambiguous *AmbiguousComposeError
+	if !errors.As(err, &ambiguous) || len(ambiguous.Matches) != 2 {
+		t.Errorf("err = %v, want *AmbiguousComposeError with 2 matches", err)
+	}
+
+	if _, err := FindCompose(ctx, client, "blog", "", "web"); !errors.Is(err, ErrComposeNotFound) {
+		t.Errorf("err = %v, want ErrComposeNotFound", err)
+	}
+}
+
+func TestGetCompose_ResolvesName(t *testing.T) {
+	ts := composeProjectsServer(t)
+	defer ts.Close()
+
+	client, err := NewClient(ts.URL, "key")
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+
+	out, err := GetCompose(context.Background(), client, "", "ghost")
+	if err != nil {
+		t.Fatalf("GetCompose error: %v", err)
+	}
+	if out["composeId"] != "cmp-4" {
+		t.Errorf("composeId = %v, want %v", out["composeId"], "cmp-4")
+	}
+}
+
+func TestGetComposeByID_DecodesNestedResources(t *testing.T) {
+	t.Helper()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/compose.one" || r.URL.Query().Get("composeId") != "cmp-1" {
+			t.Errorf("request = %s, want /api/compose.one?composeId=cmp-1", r.URL)
+		}
+		_, _ = w.Write([]byte(`{
+			"composeId": "cmp-1",
+			"name": "web",
+			"composeStatus": "done",
+			"sourceType": "raw",
+			"composeFile": "services: {}",
+			"env": "A=1",
+			"description": null,
+			"suffix": "abc",
+			"domains": [{"domainId": "dom-1", "host": "shop.example.com", "port": 80, "https": true}],
+			"mounts": [{"mountId": "mnt-1", "type": "volume", "volumeName": "data", "mountPath": "/data"}],
+			"deployments": [{"deploymentId": "dep-1", "status": "done"}]
+		}`))
+	}))
+	defer ts.Close()
+
+	client, err := NewClient(ts.URL, "key")
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+
+	cmp, err := GetComposeByID(context.Background(), client, "cmp-1")
+	if err != nil {
+		t.Fatalf("GetComposeByID error: %v", err)
+	}
+	if cmp.ComposeStatus != "done" || cmp.SourceType != "raw" || cmp.ComposeFile != "services: {}" {
+		t.Errorf("compose = %+v, want status done, source raw and the compose file", cmp)
+	}
+	if cmp.Env == nil || *cmp.Env != "A=1" || cmp.Description != nil {
+		t.Errorf("Env = %v, Description = %v, want A=1 and nil", cmp.Env, cmp.Description)
+	}
+	if len(cmp.Domains) != 1 || cmp.Domains[0].Host != "shop.example.com" || !cmp.Domains[0].HTTPS {
+		t.Errorf("Domains = %+v, want shop.example.com with https", cmp.Domains)
+	}
+	if len(cmp.Mounts) != 1 || cmp.Mounts[0].VolumeName == nil || *cmp.Mounts[0].VolumeName != "data" {
+		t.Errorf("Mounts = %+v, want volume data", cmp.Mounts)
+	}
+	if len(cmp.Deployments) != 1 || cmp.Deployments[0].DeploymentID != "dep-1" {
+		t.Errorf("Deployments = %+v, want dep-1", cmp.Deployments)
+	}
+
+	var raw map[string]any
+	if err := json.Unmarshal(cmp.Raw, &raw); err != nil {
+		t.Fatalf("Raw is not valid JSON: %v", err)
+	}
+	if raw["suffix"] != "abc" {
+		t.Errorf("Raw suffix = %v, want %v", raw["suffix"], "abc")
+	}
+}
+
+func TestSaveComposeEnv_UpdatesOnlyEnv(t *testing.T) {
+	t.Helper()
+
+	var gotPath string
+	var gotBody map[string]any
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		_ = json.NewDecoder(r.Body).Decode(&gotBody)
+		_, _ = w.Write([]byte(`{"composeId": "cmp-1"}`))
+	}))
+	defer ts.Close()
+
+	client, err := NewClient(ts.URL, "key")
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+
+	if err := SaveComposeEnv(context.Background(), client, "cmp-1", map[string]string{"B": "2", "A": "1"}); err != nil {
+		t.Fatalf("SaveComposeEnv error: %v", err)
+	}
+	if gotPath != "/api/compose.update" {
+		t.Errorf("path = %q, want %q", gotPath, "/api/compose.update")
+	}
+	if len(gotBody) != 2 || gotBody["composeId"] != "cmp-1" || gotBody["env"] != "A=1\nB=2" {
+		t.Errorf("body = %v, want only composeId cmp-1 and env A=1\\nB=2", gotBody)
+	}
+}
+
+func TestGetComposeEnv_ParsesWithoutExpanding(t *testing.T) {
+	t.Helper()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"composeId": "cmp-1", "env": "# db\nDB_HOST=db\nDB_URL=postgres://${DB_HOST}"}`))
+	}))
+	defer ts.Close()
+
+	client, err := NewClient(ts.URL, "key")
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+
+	env, err := GetComposeEnv(context.Background(), client, "cmp-1")
+	if err != nil {
+		t.Fatalf("GetComposeEnv error: %v", err)
+	}
+	if len(env) != 2 || env["DB_HOST"] != "db" || env["DB_URL"] != "postgres://${DB_HOST}" {
+		t.Errorf("env = %v, want DB_HOST=db and DB_URL unexpanded", env)
+	}
+}
+
+func TestComposeEnvDocument_KeepsLinesAsWritten(t *testing.T) {
+	t.Helper()
+
+	var saved string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			_, _ = w.Write([]byte(`{"composeId": "cmp-1", "env": "# db\nDB_URL=postgres://${DB_HOST}\nDB_HOST=db"}`))
+			return
+		}
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		saved, _ = body["env"].(string)
+	}))
+	defer ts.Close()
+
+	client, err := NewClient(ts.URL, "key")
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	ctx := context.Background()
+
+	env, err := GetComposeEnvDocument(ctx, client, "cmp-1")
+	if err != nil {
+		t.Fatalf("GetComposeEnvDocument error: %v", err)
+	}
+	env.Set("DB_HOST", "db2")
+	if err := SaveComposeEnvDocument(ctx, client, "cmp-1", env); err != nil {
+		t.Fatalf("SaveComposeEnvDocument error: %v", err)
+	}
+	if want := "# db\nDB_URL=postgres://${DB_HOST}\nDB_HOST=db2"; saved != want {
+		t.Errorf("saved env = %q, want %q", saved, want)
+	}
+}
+
+func TestSaveComposeSource_Payloads(t *testing.T) {
+	t.Helper()
+
+	var gotBody map[string]any
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/compose.update" {
+			t.Errorf("path = %q, want /api/compose.update", r.URL.Path)
+		}
+		gotBody = nil
+		_ = json.NewDecoder(r.Body).Decode(&gotBody)
+		_, _ = w.Write([]byte(`{"composeId": "cmp-1"}`))
+	}))
+	defer ts.Close()
+
+	client, err := NewClient(ts.URL, "key")
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	ctx := context.Background()
+
+	err = SaveComposeSource(ctx, client, "cmp-1", ComposeSource{Type: "git", URL: "git@example.com:team/app.git", Branch: "main", SSHKeyID: "ssh-1"})
+	if err != nil {
+		t.Fatalf("SaveComposeSource(git) error: %v", err)
+	}
+	if gotBody["sourceType"] != "git" || gotBody["customGitUrl"] != "git@example.com:team/app.git" ||
+		gotBody["customGitBranch"] != "main" || gotBody["customGitSSHKeyId"] != "ssh-1" || gotBody["composePath"] != DefaultComposePath {
+		t.Errorf("git body = %v", gotBody)
+	}
+
+	err = SaveComposeSource(ctx, client, "cmp-1", ComposeSource{
+		Type: ProviderGitLab, ProviderID: "gl-1", Repository: "team/backend/api", Branch: "prod",
+		ComposePath: "deploy/compose.yml", GitLabProjectID: "42",
+	})
+	if err != nil {
+		t.Fatalf("SaveComposeSource(gitlab) error: %v", err)
+	}
+	if gotBody["gitlabId"] != "gl-1" || gotBody["gitlabOwner"] != "team/backend" || gotBody["gitlabRepository"] != "api" ||
+		gotBody["gitlabPathNamespace"] != "team/backend/api" || gotBody["gitlabProjectId"] != float64(42) ||
+		gotBody["gitlabBranch"] != "prod" || gotBody["composePath"] != "deploy/compose.yml" {
+		t.Errorf("gitlab body = %v", gotBody)
+	}
+
+	for _, src := range []ComposeSource{
+		{Type: "git", Branch: "main"},
+		{Type: ProviderGitHub, ProviderID: "gh-1", Repository: "app", Branch: "main"},
+		{Type: "svn", Branch: "main"},
+	} {
+		if err := SaveComposeSource(ctx, client, "cmp-1", src); err == nil {
+			t.Errorf("SaveComposeSource(%+v) error = nil, want an error", src)
+		}
+	}
+}
+
+func TestComposeLifecycle_CallsEndpoints(t *testing.T) {
+	t.Helper()
+
+	var gotPath string
+	var gotBody map[string]any
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		_ = json.NewDecoder(r.Body).Decode(&gotBody)
+	}))
+	defer ts.Close()
+
+	client, err := NewClient(ts.URL, "key")
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+
+	cases := []struct {
+		call func(context.Context, *Client, string) error
+		path string
+	}{
+		{DeployCompose, "/api/compose.deploy"},
+		{RedeployCompose, "/api/compose.redeploy"},
+		{StopCompose, "/api/compose.stop"},
+		{StartCompose, "/api/compose.start"},
+		{CancelComposeDeployment, "/api/compose.cancelDeployment"},
+		{CleanComposeQueues, "/api/compose.cleanQueues"},
+	}
+	for _, tc := range cases {
+		gotBody = nil
+		if err := tc.call(context.Background(), client, "cmp-1"); err != nil {
+			t.Fatalf("%s error: %v", tc.path, err)
+		}
+		if gotPath != tc.path || gotBody["composeId"] != "cmp-1" {
+			t.Errorf("request = %s %v, want %s with composeId cmp-1", gotPath, gotBody, tc.path)
+		}
+	}
+}
@@ -0,0 +1,278 @@
+package dokploy
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Compose files are sent to Dokploy as text and only parsed by docker
+// compose when the app is deployed. The checks here catch the mistakes
+// that would otherwise only show up as a failed deployment.
+
+// ErrInvalidComposeFile is wrapped by the errors of ParseComposeFile.
+var ErrInvalidComposeFile = errors.New("invalid compose file")
+
+// ComposeFileError is returned by ParseComposeFile for a file docker
+// compose would reject. Line is 0 when the error is not tied to a line.
+type ComposeFileError struct {
+	Line    int
+	Message string
+}
+
+func (e *ComposeFileError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("%s: line %d: %s", ErrInvalidComposeFile, e.Line, e.Message)
+	}
+	return fmt.Sprintf("%s: %s", ErrInvalidComposeFile, e.Message)
+}
+
+func (e *ComposeFileError) Unwrap() error {
+	return ErrInvalidComposeFile
+}
+
+// Severities of a ComposeProblem.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// ComposeProblem is an issue found by ValidateComposeFile. Errors make
+// docker compose reject the file; warnings usually mean it will not run as
+// intended. Line is 0 when the problem is not tied to a line.
+type ComposeProblem struct {
+	Severity string `json:"severity"`
+	Line     int    `json:"line,omitempty"`
+	Message  string `json:"message"`
+}
+
+func (p ComposeProblem) String() string {
+	if p.Line > 0 {
+		return fmt.Sprintf("%s: line %d: %s", p.Severity, p.Line, p.Message)
+	}
+	return fmt.Sprintf("%s: %s", p.Severity, p.Message)
+}
+
+// ComposeVariable is a ${VAR} or $VAR reference in a compose file, which
+// docker compose interpolates from the env vars Dokploy writes next to it.
+type ComposeVariable struct {
+	Name string `json:"name"`
+	Line int    `json:"line"`
+	// Operator is what follows the name in ${NAME<op>arg}: "-" or ":-"
+	// give a default, "?" or ":?" make the variable required, and "+" or
+	// ":+" substitute arg when it is set. It is "" for plain references.
+	Operator string `json:"operator,omitempty"`
+	Arg      string `json:"arg,omitempty"`
+}
+
+// ComposeFile is a parsed compose file.
+type ComposeFile struct {
+	// Services are the names of the services, in file order.
+	Services []string
+	// Variables are the interpolated references, in file order.
+	Variables []ComposeVariable
+}
+
+// ParseComposeFile parses a compose file and checks that it is a mapping
+// with a non-empty services section, and that its ${...} references are
+// well formed. It returns a *ComposeFileError if it is not.
+func ParseComposeFile(content []byte) (*ComposeFile, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		// The message of a syntax error already has its line.
+		return nil, &ComposeFileError{Message: strings.TrimPrefix(err.Error(), "yaml: ")}
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		return nil, &ComposeFileError{Message: "the file is empty"}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, composeFileErrorf(root.Line, "expected a mapping of top-level keys such as services")
+	}
+
+	f := &ComposeFile{}
+	services := mappingValue(root, "services")
+	switch {
+	case services == nil:
+		return nil, composeFileErrorf(0, "no services section; this does not look like a compose file")
+	case services.Kind != yaml.MappingNode || len(services.Content) == 0:
+		return nil, composeFileErrorf(services.Line, "services must be a mapping of service names to their definitions")
+	}
+	for i := 0; i+1 < len(services.Content); i += 2 {
+		name, def := services.Content[i], services.Content[i+1]
+		if def.Kind != yaml.MappingNode {
+			return nil, composeFileErrorf(def.Line, "service %q must be a mapping", name.Value)
+		}
+		f.Services = append(f.Services, name.Value)
+	}
+
+	if err := f.collectVariables(root); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func composeFileErrorf(line int, format string, args ...any) error {
+	return &ComposeFileError{Line: line, Message: fmt.Sprintf(format, args...)}
+}
+
+// HasService reports whether the file defines the named service.
+func (f *ComposeFile) HasService(name string) bool {
+	return slices.Contains(f.Services, name)
+}
+
+// CheckVariables reports the references that docker compose cannot
+// resolve from env: an unset required variable is an error, and an unset
+// variable without a default a warning, since it is replaced by an empty
+// string.
+func (f *ComposeFile) CheckVariables(env *EnvDocument) []ComposeProblem {
+	var problems []ComposeProblem
+	for _, v := range f.Variables {
+		value, set := "", false
+		if env != nil {
+			value, set = env.Get(v.Name)
+		}
+		switch v.Operator {
+		case "":
+			if !set {
+				problems = append(problems, ComposeProblem{Severity: SeverityWarning, Line: v.Line, Message: fmt.Sprintf("%s is not set and will be empty", v.Name)})
+			}
+		case "?", ":?":
+			if !set || (v.Operator == ":?" && value == "") {
+				msg := fmt.Sprintf("%s is required but not set", v.Name)
+				if set {
+					msg = fmt.Sprintf("%s is required but empty", v.Name)
+				}
+				if v.Arg != "" {
+					msg += ": " + v.Arg
+				}
+				problems = append(problems, ComposeProblem{Severity: SeverityError, Line: v.Line, Message: msg})
+			}
+		}
+	}
+	return problems
+}
+
+// ValidateComposeFile parses a compose file and checks its references
+// against the env vars it will be deployed with. A file that cannot be
+// parsed is reported as a single error.
+func ValidateComposeFile(content []byte, env *EnvDocument) (*ComposeFile, []ComposeProblem) {
+	f, err := ParseComposeFile(content)
+	var fileErr *ComposeFileError
+	if errors.As(err, &fileErr) {
+		return nil, []ComposeProblem{{Severity: SeverityError, Line: fileErr.Line, Message: fileErr.Message}}
+	}
+	return f, f.CheckVariables(env)
+}
+
+// HasComposeErrors reports whether any of problems is an error.
+func HasComposeErrors(problems []ComposeProblem) bool {
+	return slices.ContainsFunc(problems, func(p ComposeProblem) bool { return p.Severity == SeverityError })
+}
+
+// mappingValue returns the value of key in a mapping node, or nil.
+func mappingValue(m *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return m.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// collectVariables records the references in the scalar values under n.
+// Keys are not interpolated by docker compose.
+func (f *ComposeFile) collectVariables(n *yaml.Node) error {
+	switch n.Kind {
+	case yaml.ScalarNode:
+		return f.scanVariables(n.Value, n.Line)
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			if err := f.collectVariables(n.Content[i+1]); err != nil {
+				return err
+			}
+		}
+	case yaml.SequenceNode:
+		for _, c := range n.Content {
+			if err := f.collectVariables(c); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// scanVariables records the references in s. References nested in the
+// argument of ${NAME:-default} are not recorded, since they are only used
+// when NAME is unset. "$$" is a literal "$".
+func (f *ComposeFile) scanVariables(s string, line int) error {
+	for i := 0; i < len(s); i++ {
+		if s[i] != '$' || i+1 == len(s) {
+			continue
+		}
+		switch next := s[i+1]; {
+		case next == '$':
+			i++
+		case next == '{':
+			end := closingBrace(s, i+2)
+			if end < 0 {
+				return composeFileErrorf(line, "invalid interpolation format %q: missing }", s[i:])
+			}
+			ref := s[i+2 : end]
+			name := ref[:envNameLength(ref)]
+			if name == "" {
+				return composeFileErrorf(line, "invalid interpolation format %q", s[i:end+1])
+			}
+			v := ComposeVariable{Name: name, Line: line}
+			rest := ref[len(name):]
+			for _, op := range []string{":-", ":?", ":+", "-", "?", "+"} {
+				if strings.HasPrefix(rest, op) {
+					v.Operator, v.Arg, rest = op, rest[len(op):], ""
+					break
+				}
+			}
+			if rest != "" {
+				return composeFileErrorf(line, "invalid interpolation format %q", s[i:end+1])
+			}
+			f.Variables = append(f.Variables, v)
+			i = end
+		default:
+			if n := envNameLength(s[i+1:]); n > 0 {
+				f.Variables = append(f.Variables, ComposeVariable{Name: s[i+1 : i+1+n], Line: line})
+				i += n
+			}
+		}
+	}
+	return nil
+}
+
+// closingBrace returns the index of the "}" closing a "${" whose contents
+// start at i, skipping nested references, or -1.
+func closingBrace(s string, i int) int {
+	depth := 1
+	for ; i < len(s); i++ {
+		switch {
+		case s[i] == '$' && i+1 < len(s) && s[i+1] == '{':
+			depth++
+			i++
+		case s[i] == '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// envNameLength returns the length of the variable name at the start of s.
+func envNameLength(s string) int {
+	n := 0
+	for n < len(s) && isEnvName(s[:n+1]) {
+		n++
+	}
+	return n
+}
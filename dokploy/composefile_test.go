@@ -0,0 +1,99 @@
+package dokploy
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseComposeFile_ServicesAndVariables(t *testing.T) {
+	t.Helper()
+
+	f, err := ParseComposeFile([]byte(`services:
+  web:
+    image: "nginx:${NGINX_TAG:-latest}"
+    environment:
+      - DATABASE_URL=postgres://$DB_USER@db/app
+      - PRICE=$$5
+  db:
+    image: postgres
+    environment:
+      POSTGRES_PASSWORD: ${DB_PASSWORD:?set the database password}
+`))
+	if err != nil {
+		t.Fatalf("ParseComposeFile error: %v", err)
+	}
+	if want := []string{"web", "db"}; !reflect.DeepEqual(f.Services, want) {
+		t.Errorf("Services = %v, want %v", f.Services, want)
+	}
+	want := []ComposeVariable{
+		{Name: "NGINX_TAG", Line: 3, Operator: ":-", Arg: "latest"},
+		{Name: "DB_USER", Line: 5},
+		{Name: "DB_PASSWORD", Line: 10, Operator: ":?", Arg: "set the database password"},
+	}
+	if !reflect.DeepEqual(f.Variables, want) {
+		t.Errorf("Variables = %+v, want %+v", f.Variables, want)
+	}
+	if !f.HasService("db") || f.HasService("cache") {
+		t.Errorf("HasService(db), HasService(cache) = %v, %v; want true, false", f.HasService("db"), f.HasService("cache"))
+	}
+}
+
+func TestParseComposeFile_Errors(t *testing.T) {
+	t.Helper()
+
+	tests := []struct {
+		name, content string
+		line          int
+	}{
+		{"syntax", "services:\n  web:\n    image: [nginx\n", 0},
+		{"empty", "", 0},
+		{"not a mapping", "- web\n", 1},
+		{"no services", "version: '3'\nvolumes: {}\n", 0},
+		{"service not a mapping", "services:\n  web: nginx\n", 2},
+		{"unterminated reference", "services:\n  web:\n    image: nginx:${TAG\n", 3},
+		{"bad reference", "services:\n  web:\n    image: nginx:${TAG!}\n", 3},
+	}
+	for _, tt := range tests {
+		_, err := ParseComposeFile([]byte(tt.content))
+		var fileErr *ComposeFileError
+		if !errors.As(err, &fileErr) || !errors.Is(err, ErrInvalidComposeFile) {
+			t.Errorf("%s: error = %v, want a *ComposeFileError", tt.name, err)
+			continue
+		}
+		if fileErr.Line != tt.line {
+			t.Errorf("%s: line = %d, want %d (%v)", tt.name, fileErr.Line, tt.line, err)
+		}
+	}
+}
+
+func TestValidateComposeFile_ChecksVariablesAgainstEnv(t *testing.T) {
+	t.Helper()
+
+	content := []byte(`services:
+  web:
+    image: nginx:${TAG}
+    environment:
+      HOST: ${HOST}
+      KEY: ${API_KEY:?}
+      SECRET: ${SECRET?}
+      MODE: ${MODE-dev}
+`)
+	env, err := ParseEnvDocument("TAG=1.27\nAPI_KEY=\n", nil)
+	if err != nil {
+		t.Fatalf("ParseEnvDocument error: %v", err)
+	}
+
+	_, problems := ValidateComposeFile(content, env)
+	want := []ComposeProblem{
+		{Severity: SeverityWarning, Line: 5, Message: "HOST is not set and will be empty"},
+		{Severity: SeverityError, Line: 6, Message: "API_KEY is required but empty"},
+		{Severity: SeverityError, Line: 7, Message: "SECRET is required but not set"},
+	}
+	if !reflect.DeepEqual(problems, want) {
+		t.Errorf("problems = %+v, want %+v", problems, want)
+	}
+	if !HasComposeErrors(problems) || HasComposeErrors(problems[:1]) {
+		t.Errorf("HasComposeErrors does not tell errors from warnings")
+	}
+}
@@ -0,0 +1,359 @@
+package dokploy
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Dokploy stores a single compose file per compose app. A base file and
+// its overrides, as passed to docker compose with repeated -f, are merged
+// into one before they are uploaded.
+
+// NamedComposeFile is a compose file to merge, with the name (usually its
+// path) its errors are reported under.
+type NamedComposeFile struct {
+	Name    string
+	Content []byte
+}
+
+// MergeComposeFiles merges compose files in order, each overriding the
+// ones before it, following docker compose's merge rules:
+//
+//   - mappings are merged key by key, and scalars replaced;
+//   - environment, labels, extra_hosts, sysctls, annotations, build args,
+//     depends_on and networks are merged by name, whether written as lists
+//     or mappings;
+//   - volumes and devices are merged by container path, and secrets and
+//     configs by source;
+//   - command, entrypoint and healthcheck tests are replaced;
+//   - ports, dns, env_file, cap_add and similar lists are appended without
+//     duplicates, and other lists appended;
+//   - the !reset tag removes a value and !override replaces it instead of
+//     merging.
+//
+// A single file is returned unchanged. Otherwise the result is re-encoded:
+// anchors and aliases are expanded, and comments are kept where they
+// survive the merge. Variables are not interpolated.
+func MergeComposeFiles(files []NamedComposeFile) ([]byte, error) {
+	if len(files) == 0 {
+		return nil, &ComposeFileError{Message: "no compose files to merge"}
+	}
+	if len(files) == 1 {
+		return files[0].Content, nil
+	}
+
+	var merged *yaml.Node
+	for _, f := range files {
+		var doc yaml.Node
+		if err := yaml.Unmarshal(f.Content, &doc); err != nil {
+			return nil, fmt.Errorf("%s: %w", f.Name, &ComposeFileError{Message: strings.TrimPrefix(err.Error(), "yaml: ")})
+		}
+		if len(doc.Content) == 0 {
+			continue // an empty override changes nothing
+		}
+		root, err := expandAliases(doc.Content[0], map[*yaml.Node]bool{})
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", f.Name, err)
+		}
+		if root.Kind != yaml.MappingNode {
+			return nil, fmt.Errorf("%s: %w", f.Name, composeFileErrorf(root.Line, "expected a mapping of top-level keys such as services"))
+		}
+		if merged == nil {
+			merged = root
+			continue
+		}
+		merged = mergeComposeNode(merged, root, nil)
+	}
+	if merged == nil {
+		return nil, &ComposeFileError{Message: "the files are empty"}
+	}
+	clearMergeTags(merged)
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(merged); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Tags that control how an override is merged.
+const (
+	resetTag    = "!reset"
+	overrideTag = "!override"
+)
+
+// How lists under a service are merged, by their dotted path below the
+// service. Lists not named here are appended.
+var (
+	composeReplaceKeys = []string{"command", "entrypoint", "healthcheck.test"}
+	composeNamedKeys   = []string{"environment", "labels", "extra_hosts", "sysctls", "annotations", "depends_on", "networks", "build.args", "build.labels"}
+	composeUniqueKeys  = []string{"ports", "expose", "dns", "dns_search", "dns_opt", "env_file", "tmpfs", "external_links", "links", "cap_add", "cap_drop", "security_opt", "group_add", "profiles", "volumes_from"}
+)
+
+// mergeComposeNode merges override into base and returns the result.
+// path is the list of mapping keys leading to them.
+func mergeComposeNode(base, override *yaml.Node, path []string) *yaml.Node {
+	if override.Tag == overrideTag {
+		return override
+	}
+
+	key := serviceKey(path)
+	switch {
+	case key == "":
+	case slices.Contains(composeReplaceKeys, key):
+		return override
+	case key == "build":
+		base, override = buildMapping(base), buildMapping(override)
+	case slices.Contains(composeNamedKeys, key):
+		if base.Kind == yaml.SequenceNode && override.Kind == yaml.SequenceNode {
+			return mergeSequence(base, override, func(n *yaml.Node) string { return namedItemKey(key, n) })
+		}
+		base, override = namedMapping(key, base), namedMapping(key, override)
+	case base.Kind != yaml.SequenceNode || override.Kind != yaml.SequenceNode:
+	case key == "volumes" || key == "devices":
+		return mergeSequence(base, override, mountTarget)
+	case key == "secrets" || key == "configs":
+		return mergeSequence(base, override, func(n *yaml.Node) string { return mountField(n, "source", -1) })
+	case slices.Contains(composeUniqueKeys, key):
+		return mergeSequence(base, override, nodeText)
+	}
+
+	switch {
+	case base.Kind == yaml.MappingNode && override.Kind == yaml.MappingNode:
+		return mergeMapping(base, override, path)
+	case base.Kind == yaml.SequenceNode && override.Kind == yaml.SequenceNode:
+		return mergeSequence(base, override, nil)
+	}
+	return override
+}
+
+// mergeMapping merges the keys of override into base, keeping the order of
+// base and adding new keys at the end.
+func mergeMapping(base, override *yaml.Node, path []string) *yaml.Node {
+	for i := 0; i+1 < len(override.Content); i += 2 {
+		key, value := override.Content[i], override.Content[i+1]
+		j := mappingIndex(base, key.Value)
+		switch {
+		case value.Tag == resetTag && j >= 0:
+			base.Content = slices.Delete(base.Content, j, j+2)
+		case value.Tag == resetTag:
+		case j >= 0:
+			base.Content[j+1] = mergeComposeNode(base.Content[j+1], value, append(slices.Clip(path), key.Value))
+		default:
+			base.Content = append(base.Content, key, value)
+		}
+	}
+	return base
+}
+
+// mergeSequence appends the items of override to base. With a key
+// function, an item replaces the base item with the same key instead.
+func mergeSequence(base, override *yaml.Node, key func(*yaml.Node) string) *yaml.Node {
+	for _, item := range override.Content {
+		i := -1
+		if key != nil {
+			k := key(item)
+			i = slices.IndexFunc(base.Content, func(n *yaml.Node) bool { return key(n) == k })
+		}
+		if i >= 0 {
+			base.Content[i] = item
+		} else {
+			base.Content = append(base.Content, item)
+		}
+	}
+	return base
+}
+
+// serviceKey returns the dotted path below a service, such as
+// "build.args", or "" if path is not inside services.<name>.
+func serviceKey(path []string) string {
+	if len(path) < 3 || path[0] != "services" {
+		return ""
+	}
+	return strings.Join(path[2:], ".")
+}
+
+func mappingIndex(m *yaml.Node, key string) int {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// namedItemKey returns the name of an item of a list that can also be
+// written as a mapping: the KEY of KEY=VALUE, the host of host:ip, or the
+// service or network name.
+func namedItemKey(key string, n *yaml.Node) string {
+	sep := "="
+	if key == "extra_hosts" {
+		sep = "=:"
+	}
+	if key == "depends_on" || key == "networks" {
+		sep = ""
+	}
+	if i := strings.IndexAny(n.Value, sep); sep != "" && i >= 0 {
+		return n.Value[:i]
+	}
+	return n.Value
+}
+
+// namedMapping converts the list form of a named key to its mapping form,
+// so a list can be merged with a mapping.
+func namedMapping(key string, n *yaml.Node) *yaml.Node {
+	if n.Kind != yaml.SequenceNode {
+		return n
+	}
+	m := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Line: n.Line, HeadComment: n.HeadComment}
+	for _, item := range n.Content {
+		name := namedItemKey(key, item)
+		var value *yaml.Node
+		switch {
+		case key == "depends_on":
+			value = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Content: []*yaml.Node{scalarNode("condition"), scalarNode("service_started")}}
+		case len(name) < len(item.Value):
+			value = scalarNode(item.Value[len(name)+1:])
+		default:
+			value = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}
+		}
+		m.Content = append(m.Content, scalarNode(name), value)
+	}
+	return m
+}
+
+// buildMapping converts build: ./dir to build: {context: ./dir}.
+func buildMapping(n *yaml.Node) *yaml.Node {
+	if n.Kind != yaml.ScalarNode || n.Tag == resetTag {
+		return n
+	}
+	return &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Line: n.Line, Content: []*yaml.Node{scalarNode("context"), {Kind: yaml.ScalarNode, Tag: "!!str", Value: n.Value}}}
+}
+
+// mountTarget returns the container path of a volume or device, given as
+// source:target[:mode] or as a mapping with a target.
+func mountTarget(n *yaml.Node) string {
+	return mountField(n, "target", 1)
+}
+
+// mountField returns a field of a long-form mount, or the part of the
+// short form at index, with -1 meaning the whole string.
+func mountField(n *yaml.Node, field string, index int) string {
+	if n.Kind == yaml.MappingNode {
+		if i := mappingIndex(n, field); i >= 0 {
+			return n.Content[i+1].Value
+		}
+		return nodeText(n)
+	}
+	parts := strings.Split(n.Value, ":")
+	if index < 0 || len(parts) == 1 {
+		return n.Value
+	}
+	return parts[min(index, len(parts)-1)]
+}
+
+// nodeText returns n encoded as YAML, to compare nodes by value.
+func nodeText(n *yaml.Node) string {
+	if n.Kind == yaml.ScalarNode {
+		return n.Value
+	}
+	b, err := yaml.Marshal(n)
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
+
+func scalarNode(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+}
+
+// expandAliases returns a copy of n with aliases replaced by copies of
+// what they refer to, and << merge keys resolved, so that merging one part
+// of the file cannot change another. expanding holds the anchored nodes
+// being expanded, to reject an alias to a node that contains it.
+func expandAliases(n *yaml.Node, expanding map[*yaml.Node]bool) (*yaml.Node, error) {
+	if n.Kind == yaml.AliasNode {
+		if expanding[n.Alias] {
+			return nil, composeFileErrorf(n.Line, "alias *%s refers to a node that contains it", n.Value)
+		}
+		return expandAliases(n.Alias, expanding)
+	}
+	if n.Anchor != "" {
+		expanding[n] = true
+		defer delete(expanding, n)
+	}
+	c := *n
+	c.Anchor = ""
+	c.Content = nil
+	if n.Kind != yaml.MappingNode {
+		for _, child := range n.Content {
+			e, err := expandAliases(child, expanding)
+			if err != nil {
+				return nil, err
+			}
+			c.Content = append(c.Content, e)
+		}
+		return &c, nil
+	}
+
+	var inherited []*yaml.Node
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		key := n.Content[i]
+		value, err := expandAliases(n.Content[i+1], expanding)
+		if err != nil {
+			return nil, err
+		}
+		if key.Tag != "!!merge" {
+			k, err := expandAliases(key, expanding)
+			if err != nil {
+				return nil, err
+			}
+			c.Content = append(c.Content, k, value)
+			continue
+		}
+		// <<: *a or <<: [*a, *b]; earlier sources take precedence.
+		sources := []*yaml.Node{value}
+		if value.Kind == yaml.SequenceNode {
+			sources = value.Content
+		}
+		for _, src := range sources {
+			inherited = append(inherited, src.Content...)
+		}
+	}
+	for i := 0; i+1 < len(inherited); i += 2 {
+		if mappingIndex(&c, inherited[i].Value) < 0 {
+			c.Content = append(c.Content, inherited[i], inherited[i+1])
+		}
+	}
+	return &c, nil
+}
+
+// clearMergeTags removes values still tagged !reset, as in a base file,
+// and the !override tags, once the files are merged.
+func clearMergeTags(n *yaml.Node) {
+	if n.Tag == overrideTag {
+		n.Tag = ""
+	}
+	if n.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(n.Content); {
+			if n.Content[i+1].Tag == resetTag {
+				n.Content = slices.Delete(n.Content, i, i+2)
+				continue
+			}
+			i += 2
+		}
+	}
+	for _, child := range n.Content {
+		clearMergeTags(child)
+	}
+}
@@ -0,0 +1,176 @@
+package dokploy
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMergeComposeFiles_OverrideRules(t *testing.T) {
+	t.Helper()
+
+	base := `x-logging: &logging
+  driver: json-file
+services:
+  web:
+    image: ghcr.io/acme/web:1.0 # pinned
+    command: ["serve", "--dev"]
+    environment:
+      - LOG_LEVEL=debug
+      - PORT=8080
+    ports:
+      - "8080:8080"
+    volumes:
+      - ./data:/data
+      - cache:/cache
+    depends_on:
+      - db
+    logging: *logging
+  db:
+    image: postgres:16
+    ports:
+      - "5432:5432"
+volumes:
+  cache: {}
+`
+	prod := `services:
+  web:
+    image: ghcr.io/acme/web:1.1
+    command: ["serve"]
+    environment:
+      LOG_LEVEL: info
+    ports:
+      - "8080:8080"
+      - "9090:9090"
+    volumes:
+      - /srv/web:/data
+    depends_on:
+      cache:
+        condition: service_healthy
+    logging:
+      options:
+        max-size: 10m
+  db:
+    ports: !reset []
+  cache:
+    image: redis:7
+`
+	got, err := MergeComposeFiles([]NamedComposeFile{{"base.yml", []byte(base)}, {"prod.yml", []byte(prod)}})
+	if err != nil {
+		t.Fatalf("MergeComposeFiles error: %v", err)
+	}
+	want := `x-logging:
+  driver: json-file
+services:
+  web:
+    image: ghcr.io/acme/web:1.1
+    command: ["serve"]
+    environment:
+      LOG_LEVEL: info
+      PORT: "8080"
+    ports:
+      - "8080:8080"
+      - "9090:9090"
+    volumes:
+      - /srv/web:/data
+      - cache:/cache
+    depends_on:
+      db:
+        condition: service_started
+      cache:
+        condition: service_healthy
+    logging:
+      driver: json-file
+      options:
+        max-size: 10m
+  db:
+    image: postgres:16
+  cache:
+    image: redis:7
+volumes:
+  cache: {}
+`
+	if string(got) != want {
+		t.Errorf("merged =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestMergeComposeFiles_SingleFileUnchanged(t *testing.T) {
+	t.Helper()
+
+	content := []byte("services:\n  web:\n    image: nginx   # as written\n")
+	got, err := MergeComposeFiles([]NamedComposeFile{{"compose.yml", content}})
+	if err != nil || string(got) != string(content) {
+		t.Errorf("MergeComposeFiles = %q, %v; want the file unchanged", got, err)
+	}
+}
+
+func TestMergeComposeFiles_ReportsFile(t *testing.T) {
+	t.Helper()
+
+	_, err := MergeComposeFiles([]NamedComposeFile{
+		{"base.yml", []byte("services:\n  web:\n    image: nginx\n")},
+		{"prod.yml", []byte("services:\n  web:\n    image: [nginx\n")},
+	})
+	if !errors.Is(err, ErrInvalidComposeFile) {
+		t.Fatalf("error = %v, want ErrInvalidComposeFile", err)
+	}
+	if got := err.Error(); got[:len("prod.yml: ")] != "prod.yml: " {
+		t.Errorf("error = %q, want it to name prod.yml", got)
+	}
+}
+
+func TestMergeComposeFiles_RecursiveAlias(t *testing.T) {
+	t.Helper()
+
+	_, err := MergeComposeFiles([]NamedComposeFile{
+		{"base.yml", []byte("services: &a\n  web:\n    x: *a\n")},
+		{"prod.yml", []byte("services:\n  web:\n    image: nginx\n")},
+	})
+	var fileErr *ComposeFileError
+	if !errors.As(err, &fileErr) {
+		t.Fatalf("error = %v, want a *ComposeFileError", err)
+	}
+	if fileErr.Line != 3 {
+		t.Errorf("line = %d, want 3", fileErr.Line)
+	}
+	if got := err.Error(); got[:len("base.yml: ")] != "base.yml: " {
+		t.Errorf("error = %q, want it to name base.yml", got)
+	}
+}
+
+func TestMergeComposeFiles_ListsByKeyAndOverrideTag(t *testing.T) {
+	t.Helper()
+
+	base := `services:
+  web:
+    build: ./web
+    environment: [A=1, B=2]
+    labels:
+      traefik.enable: "true"
+      team: web
+`
+	override := `services:
+  web:
+    build:
+      args: [VERSION=2]
+    environment: [B=3, C=4]
+    labels: !override
+      team: platform
+`
+	got, err := MergeComposeFiles([]NamedComposeFile{{"a.yml", []byte(base)}, {"b.yml", []byte(override)}})
+	if err != nil {
+		t.Fatalf("MergeComposeFiles error: %v", err)
+	}
+	want := `services:
+  web:
+    build:
+      context: ./web
+      args: [VERSION=2]
+    environment: [A=1, B=3, C=4]
+    labels:
+      team: platform
+`
+	if string(got) != want {
+		t.Errorf("merged =\n%s\nwant\n%s", got, want)
+	}
+}
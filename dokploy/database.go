@@ -0,0 +1,282 @@
+package dokploy
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Each database engine has its own endpoint family with the same shape:
+// POST /api/<type>.create, GET /api/<type>.one?<type>Id=...,
+// POST /api/<type>.{deploy,stop,start,remove,update}.
+
+// DatabaseType is a database engine Dokploy can provision. Its value is the
+// prefix of the engine's API endpoints.
+type DatabaseType string
+
+const (
+	Postgres DatabaseType = "postgres"
+	MySQL    DatabaseType = "mysql"
+	MariaDB  DatabaseType = "mariadb"
+	Mongo    DatabaseType = "mongo"
+	Redis    DatabaseType = "redis"
+)
+
+// DatabaseTypes lists every supported engine.
+var DatabaseTypes = []DatabaseType{Postgres, MySQL, MariaDB, Mongo, Redis}
+
+type databaseSpec struct {
+	image       string
+	hasName     bool // databaseName
+	hasUser     bool // databaseUser
+	hasRootPass bool // databaseRootPassword
+	// passwordAtInit is set for engines that only read the password when
+	// they initialise an empty data volume.
+	passwordAtInit bool
+}
+
+var databaseSpecs = map[DatabaseType]databaseSpec{
+	Postgres: {image: "postgres:16", hasName: true, hasUser: true, passwordAtInit: true},
+	MySQL:    {image: "mysql:8", hasName: true, hasUser: true, hasRootPass: true, passwordAtInit: true},
+	MariaDB:  {image: "mariadb:11", hasName: true, hasUser: true, hasRootPass: true, passwordAtInit: true},
+	Mongo:    {image: "mongo:7", hasUser: true, passwordAtInit: true},
+	Redis:    {image: "redis:7"},
+}
+
+// ErrPasswordResetUnsupported is returned by ResetDatabasePassword for
+// engines whose password cannot be changed through Dokploy's API.
+var ErrPasswordResetUnsupported = errors.New("password reset is not supported")
+
+// SupportsPasswordReset reports whether ResetDatabasePassword can change
+// the password of t.
+func (t DatabaseType) SupportsPasswordReset() bool {
+	spec, ok := databaseSpecs[t]
+	return ok && !spec.passwordAtInit
+}
+
+// ParseDatabaseType validates a database engine name.
+func ParseDatabaseType(s string) (DatabaseType, error) {
+	t := DatabaseType(strings.ToLower(s))
+	if _, ok := databaseSpecs[t]; !ok {
+		names := make([]string, len(DatabaseTypes))
+		for i, t := range DatabaseTypes {
+			names[i] = string(t)
+		}
+		return "", fmt.Errorf("invalid database type %q, must be one of: %s", s, strings.Join(names, ", "))
+	}
+	return t, nil
+}
+
+// idField is the name of the engine's ID field, e.g. postgresId.
+func (t DatabaseType) idField() string {
+	return string(t) + "Id"
+}
+
+// DatabaseConfig describes a database to create. Empty fields get defaults:
+// the engine's default image, a database and user named after the database,
+// and generated passwords.
+type DatabaseConfig struct {
+	Name          string
+	Description   string
+	EnvironmentID string
+	DockerImage   string
+	DatabaseName  string
+	DatabaseUser  string
+	Password      string
+	RootPassword  string
+}
+
+// Database holds the fields common to every engine, as returned by
+// <type>.one. ID is read from the engine's own ID field, such as postgresId.
+type Database struct {
+	ID                string       `json:"id"`
+	Type              DatabaseType `json:"type"`
+	Name              string       `json:"name"`
+	AppName           string       `json:"appName"`
+	Description       *string      `json:"description"`
+	EnvironmentID     string       `json:"environmentId"`
+	DockerImage       string       `json:"dockerImage"`
+	DatabaseName      string       `json:"databaseName,omitempty"`
+	DatabaseUser      string       `json:"databaseUser,omitempty"`
+	ApplicationStatus string       `json:"applicationStatus"`
+	ExternalPort      *int         `json:"externalPort"`
+	CreatedAt         string       `json:"createdAt"`
+}
+
+// CreateDatabase calls POST /api/<type>.create and returns the new database
+// ID along with the config actually sent, including generated passwords.
+func CreateDatabase(ctx context.Context, client *Client, t DatabaseType, cfg DatabaseConfig) (string, DatabaseConfig, error) {
+	spec, ok := databaseSpecs[t]
+	if !ok {
+		return "", cfg, fmt.Errorf("unsupported database type %q", t)
+	}
+	if cfg.Name == "" || cfg.EnvironmentID == "" {
+		return "", cfg, errors.New("database name and environmentId are required")
+	}
+
+	if cfg.DockerImage == "" {
+		cfg.DockerImage = spec.image
+	}
+	payload := map[string]any{
+		"name":          cfg.Name,
+		"appName":       appNameFor(cfg.Name),
+		"environmentId": cfg.EnvironmentID,
+		"dockerImage":   cfg.DockerImage,
+	}
+	if cfg.Description != "" {
+		payload["description"] = cfg.Description
+	}
+	if spec.hasName {
+		if cfg.DatabaseName == "" {
+			cfg.DatabaseName = identifierFor(cfg.Name)
+		}
+		payload["databaseName"] = cfg.DatabaseName
+	}
+	if spec.hasUser {
+		if cfg.DatabaseUser == "" {
+			cfg.DatabaseUser = identifierFor(cfg.Name)
+		}
+		payload["databaseUser"] = cfg.DatabaseUser
+	}
+	if cfg.Password == "" {
+		cfg.Password = GeneratePassword()
+	}
+	payload["databasePassword"] = cfg.Password
+	if spec.hasRootPass {
+		if cfg.RootPassword == "" {
+			cfg.RootPassword = GeneratePassword()
+		}
+		payload["databaseRootPassword"] = cfg.RootPassword
+	}
+
+	var resp map[string]any
+	if err := client.do(ctx, http.MethodPost, "/api/"+string(t)+".create", payload, &resp); err != nil {
+		return "", cfg, err
+	}
+	id, _ := resp[t.idField()].(string)
+	if id == "" {
+		return "", cfg, fmt.Errorf("%s.create did not return a %s", t, t.idField())
+	}
+	return id, cfg, nil
+}
+
+// GetDatabase calls GET /api/<type>.one.
+func GetDatabase(ctx context.Context, client *Client, t DatabaseType, id string) (*Database, error) {
+	q := url.Values{}
+	q.Set(t.idField(), id)
+	var raw json.RawMessage
+	if err := client.do(ctx, http.MethodGet, "/api/"+string(t)+".one?"+q.Encode(), nil, &raw); err != nil {
+		return nil, err
+	}
+	var db Database
+	if err := json.Unmarshal(raw, &db); err != nil {
+		return nil, err
+	}
+	var ids map[string]any
+	if err := json.Unmarshal(raw, &ids); err != nil {
+		return nil, err
+	}
+	db.ID, _ = ids[t.idField()].(string)
+	db.Type = t
+	return &db, nil
+}
+
+// DeployDatabase calls POST /api/<type>.deploy.
+func DeployDatabase(ctx context.Context, client *Client, t DatabaseType, id string) error {
+	return databaseAction(ctx, client, t, "deploy", id)
+}
+
+// StopDatabase calls POST /api/<type>.stop.
+func StopDatabase(ctx context.Context, client *Client, t DatabaseType, id string) error {
+	return databaseAction(ctx, client, t, "stop", id)
+}
+
+// StartDatabase calls POST /api/<type>.start.
+func StartDatabase(ctx context.Context, client *Client, t DatabaseType, id string) error {
+	return databaseAction(ctx, client, t, "start", id)
+}
+
+// DeleteDatabase calls POST /api/<type>.remove.
+func DeleteDatabase(ctx context.Context, client *Client, t DatabaseType, id string) error {
+	return databaseAction(ctx, client, t, "remove", id)
+}
+
+// ResetDatabasePassword calls POST /api/<type>.update with a new
+// databasePassword, generating one if password is empty, and returns it.
+// Dokploy passes the password to the container on its next deploy.
+//
+// Postgres, MySQL, MariaDB and MongoDB only read the password when they
+// initialise an empty data volume, so storing a new one would leave
+// Dokploy's credentials out of step with the engine's. For those it returns
+// an error wrapping ErrPasswordResetUnsupported without changing anything.
+func ResetDatabasePassword(ctx context.Context, client *Client, t DatabaseType, id, password string) (string, error) {
+	if _, ok := databaseSpecs[t]; !ok {
+		return "", fmt.Errorf("unsupported database type %q", t)
+	}
+	if !t.SupportsPasswordReset() {
+		return "", fmt.Errorf("%w for %s: it only reads the password when its data volume is first initialised; change it inside the database, then in Dokploy", ErrPasswordResetUnsupported, t)
+	}
+	if password == "" {
+		password = GeneratePassword()
+	}
+	payload := map[string]any{
+		t.idField():        id,
+		"databasePassword": password,
+	}
+	if err := client.do(ctx, http.MethodPost, "/api/"+string(t)+".update", payload, nil); err != nil {
+		return "", err
+	}
+	return password, nil
+}
+
+func databaseAction(ctx context.Context, client *Client, t DatabaseType, action, id string) error {
+	if _, ok := databaseSpecs[t]; !ok {
+		return fmt.Errorf("unsupported database type %q", t)
+	}
+	payload := map[string]any{
+		t.idField(): id,
+	}
+	return client.do(ctx, http.MethodPost, "/api/"+string(t)+"."+action, payload, nil)
+}
+
+// GeneratePassword returns a random 24-character alphanumeric password.
+func GeneratePassword() string {
+	const chars = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+	out := make([]byte, 0, 24)
+	buf := make([]byte, 32)
+	for len(out) < cap(out) {
+		_, _ = rand.Read(buf)
+		for _, b := range buf {
+			// Skip bytes past the largest multiple of len(chars) so every
+			// character is equally likely.
+			if int(b) < 256-256%len(chars) && len(out) < cap(out) {
+				out = append(out, chars[int(b)%len(chars)])
+			}
+		}
+	}
+	return string(out)
+}
+
+var nonIdentifier = regexp.MustCompile(`[^a-z0-9]+`)
+
+// identifierFor turns a display name into a lowercase database/user name.
+func identifierFor(name string) string {
+	id := strings.Trim(nonIdentifier.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if id == "" {
+		return "app"
+	}
+	return id
+}
+
+// appNameFor derives a unique Docker service name from a display name, the
+// way the Dokploy UI does: a slug with a random suffix.
+func appNameFor(name string) string {
+	slug := strings.ReplaceAll(identifierFor(name), "_", "-")
+	return slug + "-" + strings.ToLower(GeneratePassword()[:6])
+}
@@ -0,0 +1,218 @@
+package dokploy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCreateDatabase_Postgres(t *testing.T) {
+	t.Helper()
+
+	var gotPath string
+	var gotBody map[string]any
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		if err := json.NewDecoder(r.Body).Decode(&gotBody); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"postgresId": "pg-123", "name": gotBody["name"]})
+	}))
+	defer ts.Close()
+
+	client, err := NewClient(ts.URL, "key")
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+
+	id, cfg, err := CreateDatabase(context.Background(), client, Postgres, DatabaseConfig{Name: "Orders DB", EnvironmentID: "env-1"})
+	if err != nil {
+		t.Fatalf("CreateDatabase error: %v", err)
+	}
+	if gotPath != "/api/postgres.create" {
+		t.Errorf("path = %q, want %q", gotPath, "/api/postgres.create")
+	}
+	if id != "pg-123" {
+		t.Errorf("id = %q, want %q", id, "pg-123")
+	}
+	if gotBody["environmentId"] != "env-1" || gotBody["dockerImage"] != "postgres:16" {
+		t.Errorf("body = %v, want environmentId env-1 and default image", gotBody)
+	}
+	if gotBody["databaseName"] != "orders_db" || gotBody["databaseUser"] != "orders_db" {
+		t.Errorf("databaseName/databaseUser = %v/%v, want orders_db", gotBody["databaseName"], gotBody["databaseUser"])
+	}
+	if appName, _ := gotBody["appName"].(string); !strings.HasPrefix(appName, "orders-db-") {
+		t.Errorf("appName = %q, want orders-db- prefix", appName)
+	}
+	if len(cfg.Password) != 24 || gotBody["databasePassword"] != cfg.Password {
+		t.Errorf("password = %q, want a generated 24-character password sent to the server", cfg.Password)
+	}
+	if _, ok := gotBody["databaseRootPassword"]; ok {
+		t.Errorf("postgres should not get a root password")
+	}
+}
+
+func TestCreateDatabase_EngineSpecificFields(t *testing.T) {
+	cases := []struct {
+		dbType   DatabaseType
+		want     []string
+		unwanted []string
+	}{
+		{MySQL, []string{"databaseName", "databaseUser", "databaseRootPassword"}, nil},
+		{MariaDB, []string{"databaseName", "databaseUser", "databaseRootPassword"}, nil},
+		{Mongo, []string{"databaseUser"}, []string{"databaseName", "databaseRootPassword"}},
+		{Redis, nil, []string{"databaseName", "databaseUser", "databaseRootPassword"}},
+	}
+	for _, tc := range cases {
+		var gotPath string
+		var gotBody map[string]any
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			gotPath = r.URL.Path
+			_ = json.NewDecoder(r.Body).Decode(&gotBody)
+			_ = json.NewEncoder(w).Encode(map[string]any{string(tc.dbType) + "Id": "db-1"})
+		}))
+
+		client, err := NewClient(ts.URL, "key")
+		if err != nil {
+			t.Fatalf("NewClient error: %v", err)
+		}
+		id, _, err := CreateDatabase(context.Background(), client, tc.dbType, DatabaseConfig{Name: "cache", EnvironmentID: "env-1"})
+		ts.Close()
+		if err != nil {
+			t.Fatalf("%s: CreateDatabase error: %v", tc.dbType, err)
+		}
+		if id != "db-1" || gotPath != "/api/"+string(tc.dbType)+".create" {
+			t.Errorf("%s: id = %q path = %q", tc.dbType, id, gotPath)
+		}
+		for _, f := range tc.want {
+			if _, ok := gotBody[f]; !ok {
+				t.Errorf("%s: missing %s", tc.dbType, f)
+			}
+		}
+		for _, f := range tc.unwanted {
+			if _, ok := gotBody[f]; ok {
+				t.Errorf("%s: unexpected %s", tc.dbType, f)
+			}
+		}
+	}
+}
+
+func TestGetDatabase_ReadsEngineID(t *testing.T) {
+	t.Helper()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/redis.one" || r.URL.Query().Get("redisId") != "rd-1" {
+			t.Errorf("request = %s, want /api/redis.one?redisId=rd-1", r.URL)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"redisId": "rd-1", "name": "cache", "applicationStatus": "done"})
+	}))
+	defer ts.Close()
+
+	client, err := NewClient(ts.URL, "key")
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+
+	db, err := GetDatabase(context.Background(), client, Redis, "rd-1")
+	if err != nil {
+		t.Fatalf("GetDatabase error: %v", err)
+	}
+	if db.ID != "rd-1" || db.Type != Redis || db.Name != "cache" || db.ApplicationStatus != "done" {
+		t.Errorf("db = %+v", db)
+	}
+}
+
+func TestDatabaseActions_CallEngineEndpoints(t *testing.T) {
+	t.Helper()
+
+	var gotPaths []string
+	var gotBodies []map[string]any
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		gotPaths = append(gotPaths, r.URL.Path)
+		gotBodies = append(gotBodies, body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	client, err := NewClient(ts.URL, "key")
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	ctx := context.Background()
+
+	for _, fn := range []func(context.Context, *Client, DatabaseType, string) error{
+		DeployDatabase, StopDatabase, StartDatabase, DeleteDatabase,
+	} {
+		if err := fn(ctx, client, MariaDB, "mdb-1"); err != nil {
+			t.Fatalf("action error: %v", err)
+		}
+	}
+	if _, err := ResetDatabasePassword(ctx, client, MariaDB, "mdb-1", ""); !errors.Is(err, ErrPasswordResetUnsupported) {
+		t.Errorf("ResetDatabasePassword(mariadb) error = %v, want ErrPasswordResetUnsupported", err)
+	}
+
+	want := []string{"/api/mariadb.deploy", "/api/mariadb.stop", "/api/mariadb.start", "/api/mariadb.remove"}
+	if len(gotPaths) != len(want) {
+		t.Fatalf("paths = %v, want %v", gotPaths, want)
+	}
+	for i := range want {
+		if gotPaths[i] != want[i] {
+			t.Errorf("paths[%d] = %q, want %q", i, gotPaths[i], want[i])
+		}
+		if gotBodies[i]["mariadbId"] != "mdb-1" {
+			t.Errorf("%s mariadbId = %v, want mdb-1", gotPaths[i], gotBodies[i]["mariadbId"])
+		}
+	}
+}
+
+func TestResetDatabasePassword_Redis(t *testing.T) {
+	t.Helper()
+
+	var gotPath string
+	var gotBody map[string]any
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		_ = json.NewDecoder(r.Body).Decode(&gotBody)
+	}))
+	defer ts.Close()
+	client, err := NewClient(ts.URL, "key")
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+
+	password, err := ResetDatabasePassword(context.Background(), client, Redis, "rds-1", "")
+	if err != nil {
+		t.Fatalf("ResetDatabasePassword error: %v", err)
+	}
+	if gotPath != "/api/redis.update" || gotBody["redisId"] != "rds-1" {
+		t.Errorf("request = %s %v, want /api/redis.update for rds-1", gotPath, gotBody)
+	}
+	if password == "" || gotBody["databasePassword"] != password {
+		t.Errorf("reset password = %q, body = %v", password, gotBody)
+	}
+}
+
+func TestDatabaseType_SupportsPasswordReset(t *testing.T) {
+	for _, dbType := range DatabaseTypes {
+		if got, want := dbType.SupportsPasswordReset(), dbType == Redis; got != want {
+			t.Errorf("%s.SupportsPasswordReset() = %v, want %v", dbType, got, want)
+		}
+	}
+}
+
+func TestParseDatabaseType(t *testing.T) {
+	if got, err := ParseDatabaseType("Postgres"); err != nil || got != Postgres {
+		t.Errorf("ParseDatabaseType(Postgres) = %q, %v", got, err)
+	}
+	if _, err := ParseDatabaseType("sqlite"); err == nil {
+		t.Errorf("ParseDatabaseType(sqlite) error = nil, want error")
+	}
+}
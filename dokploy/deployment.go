@@ -0,0 +1,280 @@
+package dokploy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Statuses Dokploy reports for deployments and for a compose app's
+// composeStatus, which is also "idle" before its first deploy.
+const (
+	StatusIdle    = "idle"
+	StatusRunning = "running"
+	StatusDone    = "done"
+	StatusError   = "error"
+)
+
+// Deployment is a single deployment record of a compose app or application.
+type Deployment struct {
+	DeploymentID  string `json:"deploymentId"`
+	Title         string `json:"title"`
+	Description   string `json:"description"`
+	Status        string `json:"status"`
+	LogPath       string `json:"logPath"`
+	ComposeID     string `json:"composeId"`
+	ApplicationID string `json:"applicationId"`
+	ErrorMessage  string `json:"errorMessage"`
+	// RollbackID is set on application deployments whose image Dokploy
+	// kept for rollbacks; see RollbackToDeployment.
+	RollbackID string `json:"rollbackId"`
+	CreatedAt  string `json:"createdAt"`
+	StartedAt  string `json:"startedAt"`
+	FinishedAt string `json:"finishedAt"`
+}
+
+// Duration returns how long the deployment ran, or 0 if it has not
+// finished or Dokploy did not record when it started.
+func (d Deployment) Duration() time.Duration {
+	started, err := time.Parse(time.RFC3339, d.StartedAt)
+	if err != nil {
+		return 0
+	}
+	finished, err := time.Parse(time.RFC3339, d.FinishedAt)
+	if err != nil || finished.Before(started) {
+		return 0
+	}
+	return finished.Sub(started)
+}
+
+// ErrDeploymentNotFound is returned when a deployment ID is not among the
+// deployments of the given compose app or application.
+var ErrDeploymentNotFound = errors.New("deployment not found")
+
+// ErrNoRollback is returned by RollbackToDeployment when Dokploy kept no
+// image to roll back to for the deployment.
+var ErrNoRollback = errors.New("deployment has no rollback image")
+
+// DeploymentError is returned when a deployment finishes with status error.
+// Deployment has no ID when the deploy failed before Dokploy recorded it.
+type DeploymentError struct {
+	Deployment Deployment
+}
+
+func (e *DeploymentError) Error() string {
+	msg := fmt.Sprintf("deployment %s failed", e.Deployment.DeploymentID)
+	if e.Deployment.DeploymentID == "" {
+		msg = fmt.Sprintf("deployment of compose %s failed", e.Deployment.ComposeID)
+	}
+	if e.Deployment.ErrorMessage != "" {
+		msg += ": " + e.Deployment.ErrorMessage
+	}
+	return msg
+}
+
+// ListComposeDeployments calls GET /api/deployment.allByCompose and returns
+// the deployments of a compose app.
+func ListComposeDeployments(ctx context.Context, client *Client, composeID string) ([]Deployment, error) {
+	q := url.Values{}
+	q.Set("composeId", composeID)
+	var out []Deployment
+	if err := client.do(ctx, http.MethodGet, "/api/deployment.allByCompose?"+q.Encode(), nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListApplicationDeployments calls GET /api/deployment.all and returns the
+// deployments of an application.
+func ListApplicationDeployments(ctx context.Context, client *Client, applicationID string) ([]Deployment, error) {
+	q := url.Values{}
+	q.Set("applicationId", applicationID)
+	var out []Deployment
+	if err := client.do(ctx, http.MethodGet, "/api/deployment.all?"+q.Encode(), nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// FindDeployment returns the deployment with the given ID, or an error
+// wrapping ErrDeploymentNotFound.
+func FindDeployment(deployments []Deployment, id string) (*Deployment, error) {
+	for i := range deployments {
+		if deployments[i].DeploymentID == id {
+			return &deployments[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrDeploymentNotFound, id)
+}
+
+// GetDeployment returns the deployment with the given ID from any compose
+// app or application the API key can see. Dokploy has no endpoint that
+// reads a single deployment, so this lists the deployments of each one in
+// turn; pass the owner to ListComposeDeployments or
+// ListApplicationDeployments instead when it is known.
+func GetDeployment(ctx context.Context, client *Client, id string) (*Deployment, error) {
+	projects, err := ListProjects(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range projects {
+		for _, env := range p.Environments {
+			for _, cmp := range env.Compose {
+				deployments, err := ListComposeDeployments(ctx, client, cmp.ComposeID)
+				if err != nil {
+					return nil, err
+				}
+				if d, err := FindDeployment(deployments, id); err == nil {
+					return d, nil
+				}
+			}
+			for _, app := range env.Applications {
+				deployments, err := ListApplicationDeployments(ctx, client, app.ApplicationID)
+				if err != nil {
+					return nil, err
+				}
+				if d, err := FindDeployment(deployments, id); err == nil {
+					return d, nil
+				}
+			}
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrDeploymentNotFound, id)
+}
+
+// Rollback calls POST /api/rollback.rollback, which redeploys the image
+// Dokploy kept for a rollback.
+func Rollback(ctx context.Context, client *Client, rollbackID string) error {
+	body := map[string]any{"rollbackId": rollbackID}
+	return client.do(ctx, http.MethodPost, "/api/rollback.rollback", body, nil)
+}
+
+// RollbackToDeployment rolls an application back to the image of one of
+// its earlier deployments. Dokploy only keeps these images for
+// applications with rollbacks enabled, and not for compose apps; it
+// returns an error wrapping ErrNoRollback for a deployment without one.
+func RollbackToDeployment(ctx context.Context, client *Client, applicationID, deploymentID string) (*Deployment, error) {
+	deployments, err := ListApplicationDeployments(ctx, client, applicationID)
+	if err != nil {
+		return nil, err
+	}
+	dep, err := FindDeployment(deployments, deploymentID)
+	if err != nil {
+		return nil, err
+	}
+	if dep.RollbackID == "" {
+		return nil, fmt.Errorf("%w: %s", ErrNoRollback, deploymentID)
+	}
+	if err := Rollback(ctx, client, dep.RollbackID); err != nil {
+		return nil, err
+	}
+	return dep, nil
+}
+
+// LatestComposeDeployment returns the most recently created deployment of a
+// compose app, or nil if it has never been deployed.
+func LatestComposeDeployment(ctx context.Context, client *Client, composeID string) (*Deployment, error) {
+	deployments, err := ListComposeDeployments(ctx, client, composeID)
+	if err != nil {
+		return nil, err
+	}
+	return latestDeployment(deployments), nil
+}
+
+func latestDeployment(deployments []Deployment) *Deployment {
+	var latest *Deployment
+	for i := range deployments {
+		// createdAt is an ISO 8601 timestamp, so it sorts as a string.
+		if latest == nil || deployments[i].CreatedAt > latest.CreatedAt {
+			latest = &deployments[i]
+		}
+	}
+	return latest
+}
+
+// WaitForComposeDeployment polls a compose app every interval until a
+// deployment newer than sinceID finishes, and returns it. sinceID is the ID
+// of the latest deployment before the deploy was triggered, or empty if
+// there was none. progress, if non-nil, is called whenever the deployment's
+// status changes.
+//
+// It returns a *DeploymentError if the deployment fails, and ctx.Err() if
+// ctx is done first; bound the wait with context.WithTimeout.
+func WaitForComposeDeployment(ctx context.Context, client *Client, composeID, sinceID string, interval time.Duration, progress func(Deployment)) (*Deployment, error) {
+	var lastStatus string
+	var composeRan bool
+	for {
+		latest, err := LatestComposeDeployment(ctx, client, composeID)
+		if err != nil {
+			return nil, err
+		}
+
+		if latest != nil && latest.DeploymentID != sinceID {
+			if latest.Status != lastStatus && progress != nil {
+				progress(*latest)
+			}
+			lastStatus = latest.Status
+
+			switch latest.Status {
+			case StatusDone:
+				return latest, nil
+			case StatusError:
+				return latest, &DeploymentError{Deployment: *latest}
+			}
+		} else {
+			// No deployment record yet: the deploy is queued, or failed before
+			// one was created. The compose status still reads "error" from a
+			// previous failure until the new deploy starts, so only trust it
+			// once it has been seen running.
+			status, err := composeStatus(ctx, client, composeID)
+			if err != nil {
+				return nil, err
+			}
+			switch {
+			case status == StatusRunning:
+				composeRan = true
+			case status == StatusError && composeRan:
+				return nil, &DeploymentError{Deployment: Deployment{
+					ComposeID:    composeID,
+					Status:       StatusError,
+					ErrorMessage: "failed before a deployment was recorded",
+				}}
+			}
+		}
+
+		if err := sleepCtx(ctx, interval); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// WaitForComposeStatus polls a compose app every interval until done
+// returns true for its composeStatus, and returns that status. It returns
+// ctx.Err() if ctx is done first; bound the wait with context.WithTimeout.
+func WaitForComposeStatus(ctx context.Context, client *Client, composeID string, interval time.Duration, done func(status string) bool) (string, error) {
+	for {
+		status, err := composeStatus(ctx, client, composeID)
+		if err != nil {
+			return "", err
+		}
+		if done(status) {
+			return status, nil
+		}
+		if err := sleepCtx(ctx, interval); err != nil {
+			return "", err
+		}
+	}
+}
+
+// composeStatus returns the composeStatus field of compose.one: idle,
+// running, done or error.
+func composeStatus(ctx context.Context, client *Client, composeID string) (string, error) {
+	out, err := GetComposeByID(ctx, client, composeID)
+	if err != nil {
+		return "", err
+	}
+	return out.ComposeStatus, nil
+}
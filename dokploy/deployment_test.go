@@ -0,0 +1,278 @@
+package dokploy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestListComposeDeployments_CallsAllByCompose(t *testing.T) {
+	t.Helper()
+
+	var gotPath, gotComposeID string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotComposeID = r.URL.Query().Get("composeId")
+		_ = json.NewEncoder(w).Encode([]Deployment{
+			{DeploymentID: "dep-1", Status: StatusDone, CreatedAt: "2026-02-05T09:00:00.000Z"},
+			{DeploymentID: "dep-2", Status: StatusRunning, CreatedAt: "2026-02-05T10:00:00.000Z"},
+		})
+	}))
+	defer ts.Close()
+
+	client, err := NewClient(ts.URL, "key")
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+
+	latest, err := LatestComposeDeployment(context.Background(), client, "cmp-1")
+	if err != nil {
+		t.Fatalf("LatestComposeDeployment error: %v", err)
+	}
+	if gotPath != "/api/deployment.allByCompose" || gotComposeID != "cmp-1" {
+		t.Errorf("request = %s?composeId=%s, want /api/deployment.allByCompose?composeId=cmp-1", gotPath, gotComposeID)
+	}
+	if latest == nil || latest.DeploymentID != "dep-2" {
+		t.Errorf("latest = %+v, want dep-2", latest)
+	}
+}
+
+// deploymentServer serves deployment.allByCompose from a sequence of
+// responses, repeating the last one, and compose.one with a fixed status.
+func deploymentServer(t *testing.T, composeStatus string, polls ...[]Deployment) *httptest.Server {
+	t.Helper()
+	n := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/deployment.allByCompose", func(w http.ResponseWriter, r *http.Request) {
+		resp := polls[min(n, len(polls)-1)]
+		n++
+		_ = json.NewEncoder(w).Encode(resp)
+	})
+	mux.HandleFunc("/api/compose.one", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"composeId": "cmp-1", "composeStatus": composeStatus})
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestWaitForComposeDeployment_WaitsForNewDeployment(t *testing.T) {
+	t.Helper()
+
+	old := Deployment{DeploymentID: "dep-1", Status: StatusDone, CreatedAt: "2026-02-05T09:00:00.000Z"}
+	ts := deploymentServer(t, StatusRunning,
+		[]Deployment{old},
+		[]Deployment{old, {DeploymentID: "dep-2", Status: StatusRunning, CreatedAt: "2026-02-05T10:00:00.000Z"}},
+		[]Deployment{old, {DeploymentID: "dep-2", Status: StatusDone, CreatedAt: "2026-02-05T10:00:00.000Z"}},
+	)
+	defer ts.Close()
+
+	client, err := NewClient(ts.URL, "key")
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+
+	var seen []string
+	dep, err := WaitForComposeDeployment(context.Background(), client, "cmp-1", "dep-1", time.Millisecond, func(d Deployment) {
+		seen = append(seen, d.DeploymentID+":"+d.Status)
+	})
+	if err != nil {
+		t.Fatalf("WaitForComposeDeployment error: %v", err)
+	}
+	if dep.DeploymentID != "dep-2" {
+		t.Errorf("deployment = %q, want %q", dep.DeploymentID, "dep-2")
+	}
+	if len(seen) != 2 || seen[0] != "dep-2:running" || seen[1] != "dep-2:done" {
+		t.Errorf("progress = %v, want [dep-2:running dep-2:done]", seen)
+	}
+}
+
+func TestWaitForComposeDeployment_ReturnsDeploymentError(t *testing.T) {
+	ts := deploymentServer(t, StatusError,
+		[]Deployment{{DeploymentID: "dep-2", Status: StatusError, ErrorMessage: "build failed"}},
+	)
+	defer ts.Close()
+
+	client, err := NewClient(ts.URL, "key")
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+
+	_, err = WaitForComposeDeployment(context.Background(), client, "cmp-1", "", time.Millisecond, nil)
+	var depErr *DeploymentError
+	if !errors.As(err, &depErr) {
+		t.Fatalf("err = %v, want *DeploymentError", err)
+	}
+	if depErr.Deployment.ErrorMessage != "build failed" {
+		t.Errorf("ErrorMessage = %q, want %q", depErr.Deployment.ErrorMessage, "build failed")
+	}
+}
+
+func TestWaitForComposeDeployment_IgnoresStaleComposeError(t *testing.T) {
+	// A previous failure leaves composeStatus at "error"; that alone must
+	// not fail a deploy that is still queued.
+	ts := deploymentServer(t, StatusError, []Deployment{})
+	defer ts.Close()
+
+	client, err := NewClient(ts.URL, "key")
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = WaitForComposeDeployment(ctx, client, "cmp-1", "", time.Millisecond, nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestWaitForComposeStatus_PollsUntilDone(t *testing.T) {
+	t.Helper()
+
+	statuses := []string{StatusRunning, StatusRunning, StatusIdle}
+	calls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/compose.one" {
+			t.Errorf("path = %q, want /api/compose.one", r.URL.Path)
+		}
+		status := statuses[min(calls, len(statuses)-1)]
+		calls++
+		_ = json.NewEncoder(w).Encode(map[string]any{"composeId": "cmp-1", "composeStatus": status})
+	}))
+	defer ts.Close()
+
+	client, err := NewClient(ts.URL, "key")
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+
+	status, err := WaitForComposeStatus(context.Background(), client, "cmp-1", time.Millisecond, func(s string) bool { return s == StatusIdle })
+	if err != nil {
+		t.Fatalf("WaitForComposeStatus error: %v", err)
+	}
+	if status != StatusIdle || calls != 3 {
+		t.Errorf("status = %q after %d calls, want %q after 3", status, calls, StatusIdle)
+	}
+}
+
+func TestWaitForComposeStatus_StopsAtDeadline(t *testing.T) {
+	t.Helper()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"composeId": "cmp-1", "composeStatus": StatusRunning})
+	}))
+	defer ts.Close()
+
+	client, err := NewClient(ts.URL, "key")
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = WaitForComposeStatus(ctx, client, "cmp-1", time.Millisecond, func(s string) bool { return s == StatusIdle })
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestDeploymentDuration(t *testing.T) {
+	t.Helper()
+
+	d := Deployment{StartedAt: "2026-02-05T10:00:00.000Z", FinishedAt: "2026-02-05T10:01:30.500Z"}
+	if got, want := d.Duration(), 90500*time.Millisecond; got != want {
+		t.Errorf("Duration() = %v, want %v", got, want)
+	}
+	running := Deployment{StartedAt: "2026-02-05T10:00:00.000Z"}
+	if got := running.Duration(); got != 0 {
+		t.Errorf("Duration() of a running deployment = %v, want 0", got)
+	}
+}
+
+func TestRollbackToDeployment_PostsRollbackID(t *testing.T) {
+	t.Helper()
+
+	var gotAppID string
+	var gotBody map[string]any
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/deployment.all", func(w http.ResponseWriter, r *http.Request) {
+		gotAppID = r.URL.Query().Get("applicationId")
+		_, _ = w.Write([]byte(`[
+			{"deploymentId": "dep-2", "status": "done", "rollbackId": null},
+			{"deploymentId": "dep-1", "status": "done", "rollbackId": "rb-1"}
+		]`))
+	})
+	mux.HandleFunc("/api/rollback.rollback", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&gotBody)
+		_, _ = w.Write([]byte(`true`))
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+	client, err := NewClient(ts.URL, "key")
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	ctx := context.Background()
+
+	dep, err := RollbackToDeployment(ctx, client, "app-1", "dep-1")
+	if err != nil {
+		t.Fatalf("RollbackToDeployment error: %v", err)
+	}
+	if gotAppID != "app-1" {
+		t.Errorf("applicationId = %q, want %q", gotAppID, "app-1")
+	}
+	if dep.DeploymentID != "dep-1" || gotBody["rollbackId"] != "rb-1" {
+		t.Errorf("rolled back %s with body %v, want dep-1 with rollbackId rb-1", dep.DeploymentID, gotBody)
+	}
+
+	gotBody = nil
+	if _, err := RollbackToDeployment(ctx, client, "app-1", "dep-2"); !errors.Is(err, ErrNoRollback) {
+		t.Errorf("RollbackToDeployment(dep-2) error = %v, want ErrNoRollback", err)
+	}
+	if _, err := RollbackToDeployment(ctx, client, "app-1", "dep-9"); !errors.Is(err, ErrDeploymentNotFound) {
+		t.Errorf("RollbackToDeployment(dep-9) error = %v, want ErrDeploymentNotFound", err)
+	}
+	if gotBody != nil {
+		t.Errorf("rollback.rollback was called for a deployment without a rollback image")
+	}
+}
+
+func TestGetDeployment_SearchesAllOwners(t *testing.T) {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/project.all", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[{"projectId": "p1", "environments": [{
+			"environmentId": "e1",
+			"compose": [{"composeId": "cmp-1"}],
+			"applications": [{"applicationId": "app-1"}]
+		}]}]`))
+	})
+	mux.HandleFunc("/api/deployment.allByCompose", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[{"deploymentId": "dep-1", "composeId": "cmp-1"}]`))
+	})
+	mux.HandleFunc("/api/deployment.all", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[{"deploymentId": "dep-2", "applicationId": "app-1", "logPath": "/logs/dep-2.log"}]`))
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+	client, err := NewClient(ts.URL, "key")
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	ctx := context.Background()
+
+	d, err := GetDeployment(ctx, client, "dep-2")
+	if err != nil {
+		t.Fatalf("GetDeployment error: %v", err)
+	}
+	if d.ApplicationID != "app-1" || d.LogPath != "/logs/dep-2.log" {
+		t.Errorf("deployment = %+v, want dep-2 of app-1", d)
+	}
+	if _, err := GetDeployment(ctx, client, "dep-9"); !errors.Is(err, ErrDeploymentNotFound) {
+		t.Errorf("GetDeployment(dep-9) error = %v, want ErrDeploymentNotFound", err)
+	}
+}
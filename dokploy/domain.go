@@ -15,10 +15,30 @@ type domainCreateUpdateResponse struct {
 	DomainID string `json:"domainId"`
 }
 
-type domainByComposeItem struct {
-	DomainID string `json:"domainId"`
-	Host     string `json:"host"`
-	Path     string `json:"path"`
+// Domain is a domain routed to a compose app or application, as returned by
+// domain.byComposeId and embedded in compose.one.
+type Domain struct {
+	DomainID        string `json:"domainId"`
+	Host            string `json:"host"`
+	Path            string `json:"path"`
+	Port            int    `json:"port"`
+	ServiceName     string `json:"serviceName"`
+	CertificateType string `json:"certificateType"`
+	HTTPS           bool   `json:"https"`
+	ComposeID       string `json:"composeId"`
+	CreatedAt       string `json:"createdAt"`
+}
+
+// listComposeDomains calls GET /api/domain.byComposeId for a compose.
+func listComposeDomains(ctx context.Context, client *Client, composeID string) ([]Domain, error) {
+	q := url.Values{}
+	q.Set("composeId", composeID)
+	endpoint := "/api/domain.byComposeId?" + q.Encode()
+	var items []Domain
+	if err := client.do(ctx, http.MethodGet, endpoint, nil, &items); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
 // findExistingDomainIDByCompose lists domains for a compose and returns
@@ -29,11 +49,8 @@ func findExistingDomainIDByCompose(ctx context.Context, client *Client, composeI
 	if composeID == "" {
 		return "", nil
 	}
-	q := url.Values{}
-	q.Set("composeId", composeID)
-	endpoint := "/api/domain.byComposeId?" + q.Encode()
-	var items []domainByComposeItem
-	if err := client.do(ctx, http.MethodGet, endpoint, nil, &items); err != nil {
+	items, err := listComposeDomains(ctx, client, composeID)
+	if err != nil {
 		return "", err
 	}
 	if len(items) == 0 {
@@ -59,16 +76,7 @@ func CreateOrUpdateDomain(
 	certificateType string,
 	https bool,
 ) (string, error) {
-	payload := map[string]any{
-		"host":            host,
-		"path":            path,
-		"port":            port,
-		"serviceName":     serviceName,
-		"composeId":       composeID,
-		"certificateType": certificateType,
-		"https":           https,
-		"domainType":      "compose",
-	}
+	payload := domainPayload(host, path, port, serviceName, composeID, certificateType, https)
 
 	// If no explicit id is provided, try to find an existing domain for
 	// this compose (and host/path) to update instead of creating a duplicate.
@@ -80,6 +88,24 @@ func CreateOrUpdateDomain(
 		}
 	}
 
+	return saveDomain(ctx, client, id, payload)
+}
+
+func domainPayload(host, path string, port int, serviceName, composeID, certificateType string, https bool) map[string]any {
+	return map[string]any{
+		"host":            host,
+		"path":            path,
+		"port":            port,
+		"serviceName":     serviceName,
+		"composeId":       composeID,
+		"certificateType": certificateType,
+		"https":           https,
+		"domainType":      "compose",
+	}
+}
+
+// saveDomain calls domain.update when id is set and domain.create otherwise.
+func saveDomain(ctx context.Context, client *Client, id string, payload map[string]any) (string, error) {
 	var resp domainCreateUpdateResponse
 	if id != "" {
 		payload["domainId"] = id
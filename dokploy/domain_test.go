@@ -21,7 +21,7 @@ func TestCreateDomain_WhenNoneExists_CallsDomainCreate(t *testing.T) {
 		case "/api/domain.byComposeId":
 			// return empty list -> no existing domain
 			w.Header().Set("Content-Type", "application/json")
-			_ = json.NewEncoder(w).Encode([]domainByComposeItem{})
+			_ = json.NewEncoder(w).Encode([]Domain{})
 		case "/api/domain.create":
 			if err := json.NewDecoder(r.Body).Decode(&gotCreateBody); err != nil {
 				w.WriteHeader(http.StatusBadRequest)
@@ -72,7 +72,7 @@ func TestCreateDomain_WhenExists_UsesUpdate(t *testing.T) {
 
 		switch r.URL.Path {
 		case "/api/domain.byComposeId":
-			items := []domainByComposeItem{{DomainID: "dom-1", Host: "example.com", Path: "/"}}
+			items := []Domain{{DomainID: "dom-1", Host: "example.com", Path: "/"}}
 			w.Header().Set("Content-Type", "application/json")
 			_ = json.NewEncoder(w).Encode(items)
 		case "/api/domain.update":
@@ -0,0 +1,393 @@
+package dokploy
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// Env vars of compose apps and applications are stored by Dokploy as a
+// single string in dotenv syntax.
+
+// EnvDocument is an env string kept entry by entry, so that it can be
+// changed and written back without reordering variables or dropping
+// comments, blank lines or repeated keys. The zero value is an empty
+// document.
+type EnvDocument struct {
+	entries []envEntry
+}
+
+// envEntry is a variable, or a comment or blank line when key is "".
+type envEntry struct {
+	key   string
+	value string
+	raw   string // source text, written back as is; "" once key or value change
+}
+
+// ParseEnvDocument parses env vars in dotenv syntax:
+//
+//	# comment
+//	export KEY=value        # "export" and inline comments are ignored
+//	QUOTED="line 1\nline 2" # double quotes: \n, \t, \", \\ and \$ escapes
+//	RAW='${NOT_EXPANDED}'   # single quotes: taken literally
+//	MULTI="first line
+//	second line"
+//	URL=https://${HOST}/api
+//
+// ${VAR} in unquoted and double-quoted values is replaced by VAR's value if
+// it was defined earlier in s, else by lookup. References that cannot be
+// resolved, and Dokploy's own ${{project.VAR}} references, are kept as they
+// are. With a nil lookup nothing is expanded, which is how an env string
+// read back from Dokploy should be parsed.
+func ParseEnvDocument(s string, lookup func(name string) (string, bool)) (*EnvDocument, error) {
+	p := &envParser{src: strings.ReplaceAll(s, "\r\n", "\n"), line: 1, doc: &EnvDocument{}, lookup: lookup}
+	if err := p.parse(); err != nil {
+		return nil, err
+	}
+	return p.doc, nil
+}
+
+// ParseEnv parses s like ParseEnvDocument and returns the value of each
+// variable. Later definitions of a key override earlier ones.
+func ParseEnv(s string, lookup func(name string) (string, bool)) (map[string]string, error) {
+	doc, err := ParseEnvDocument(s, lookup)
+	if err != nil {
+		return nil, err
+	}
+	return doc.Map(), nil
+}
+
+// EnvFromMap returns a document with the variables of m, sorted by key.
+func EnvFromMap(m map[string]string) *EnvDocument {
+	doc := &EnvDocument{}
+	for _, k := range slices.Sorted(maps.Keys(m)) {
+		doc.Append(k, m[k])
+	}
+	return doc
+}
+
+// Get returns the value of key. When key is defined more than once, the
+// last definition wins, as it does in Dokploy.
+func (d *EnvDocument) Get(key string) (string, bool) {
+	for i := len(d.entries) - 1; i >= 0; i-- {
+		if d.entries[i].key == key {
+			return d.entries[i].value, true
+		}
+	}
+	return "", false
+}
+
+// Keys returns the variable names in order of first definition.
+func (d *EnvDocument) Keys() []string {
+	var keys []string
+	seen := map[string]bool{}
+	for _, e := range d.entries {
+		if e.key != "" && !seen[e.key] {
+			seen[e.key] = true
+			keys = append(keys, e.key)
+		}
+	}
+	return keys
+}
+
+// Map returns the value of each variable.
+func (d *EnvDocument) Map() map[string]string {
+	m := map[string]string{}
+	for _, e := range d.entries {
+		if e.key != "" {
+			m[e.key] = e.value
+		}
+	}
+	return m
+}
+
+// Len returns the number of distinct variables.
+func (d *EnvDocument) Len() int {
+	return len(d.Keys())
+}
+
+// Set changes the value of key in place, at its last definition, or
+// appends it when it is not defined.
+func (d *EnvDocument) Set(key, value string) {
+	for i := len(d.entries) - 1; i >= 0; i-- {
+		if e := &d.entries[i]; e.key == key {
+			if e.value != value {
+				e.value, e.raw = value, ""
+			}
+			return
+		}
+	}
+	d.Append(key, value)
+}
+
+// Append adds a definition of key at the end, even if key is already
+// defined.
+func (d *EnvDocument) Append(key, value string) {
+	d.entries = append(d.entries, envEntry{key: key, value: value})
+}
+
+// Unset removes every definition of key and reports whether there was one.
+func (d *EnvDocument) Unset(key string) bool {
+	n := len(d.entries)
+	d.entries = slices.DeleteFunc(d.entries, func(e envEntry) bool { return e.key == key })
+	return len(d.entries) != n
+}
+
+// Merge sets each variable of other in d with Set, so existing keys keep
+// their place and new ones are added at the end.
+func (d *EnvDocument) Merge(other *EnvDocument) {
+	for _, e := range other.entries {
+		if e.key != "" {
+			d.Set(e.key, e.value)
+		}
+	}
+}
+
+// Concat appends all entries of other, including comments and repeated
+// keys, to d.
+func (d *EnvDocument) Concat(other *EnvDocument) {
+	d.entries = append(d.entries, other.entries...)
+}
+
+// String renders the document in dotenv syntax. Entries read by
+// ParseEnvDocument and not changed since are written as they were; others
+// are written as KEY=VALUE, double-quoted when ParseEnvDocument would
+// otherwise read the value back differently.
+func (d *EnvDocument) String() string {
+	lines := make([]string, len(d.entries))
+	for i, e := range d.entries {
+		if e.raw != "" || e.key == "" {
+			lines[i] = e.raw
+			continue
+		}
+		lines[i] = e.key + "=" + quoteEnvValue(e.value)
+	}
+	return strings.Join(lines, "\n")
+}
+
+type envParser struct {
+	src      string
+	pos      int
+	line     int
+	doc      *EnvDocument
+	lookup   func(string) (string, bool)
+	expanded bool // whether the current value had a reference replaced
+}
+
+func (p *envParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("line %d: %s", p.line, fmt.Sprintf(format, args...))
+}
+
+func (p *envParser) parse() error {
+	for p.pos < len(p.src) {
+		start := p.pos
+		lineEnd := strings.IndexByte(p.src[p.pos:], '\n')
+		if lineEnd < 0 {
+			lineEnd = len(p.src) - p.pos
+		}
+		if text := strings.TrimSpace(p.src[p.pos : p.pos+lineEnd]); text == "" || strings.HasPrefix(text, "#") {
+			p.doc.entries = append(p.doc.entries, envEntry{raw: p.src[p.pos : p.pos+lineEnd]})
+			p.pos += lineEnd
+			p.nextLine()
+			continue
+		}
+
+		end := strings.IndexAny(p.src[p.pos:], "=\n")
+		if end < 0 || p.src[p.pos+end] != '=' {
+			return p.errorf("expected KEY=VALUE")
+		}
+		key := strings.TrimSpace(p.src[p.pos : p.pos+end])
+		key = strings.TrimSpace(strings.TrimPrefix(key, "export "))
+		if key == "" || strings.ContainsAny(key, " \t") {
+			return p.errorf("invalid key %q", key)
+		}
+		p.pos += end + 1
+
+		p.expanded = false
+		value, err := p.value()
+		if err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		entry := envEntry{key: key, value: value, raw: p.src[start:p.pos]}
+		if p.expanded {
+			// The source no longer matches the value; write the value.
+			entry.raw = ""
+		}
+		p.doc.entries = append(p.doc.entries, entry)
+		p.nextLine()
+	}
+	return nil
+}
+
+// nextLine moves past the newline at p.pos, if any.
+func (p *envParser) nextLine() {
+	if p.pos < len(p.src) && p.src[p.pos] == '\n' {
+		p.pos++
+		p.line++
+	}
+}
+
+// value reads the value after "=" up to the end of its line.
+func (p *envParser) value() (string, error) {
+	for p.pos < len(p.src) && (p.src[p.pos] == ' ' || p.src[p.pos] == '\t') {
+		p.pos++
+	}
+	if p.pos >= len(p.src) {
+		return "", nil
+	}
+
+	switch p.src[p.pos] {
+	case '\'':
+		end := strings.IndexByte(p.src[p.pos+1:], '\'')
+		if end < 0 {
+			return "", p.errorf("unterminated single quote")
+		}
+		value := p.src[p.pos+1 : p.pos+1+end]
+		p.line += strings.Count(value, "\n")
+		p.pos += end + 2
+		return value, p.endOfLine()
+	case '"':
+		startLine := p.line
+		var b strings.Builder
+		for p.pos++; ; p.pos++ {
+			if p.pos >= len(p.src) {
+				p.line = startLine
+				return "", p.errorf("unterminated double quote")
+			}
+			ch := p.src[p.pos]
+			switch {
+			case ch == '"':
+				p.pos++
+				return b.String(), p.endOfLine()
+			case ch == '\\' && p.pos+1 < len(p.src):
+				p.pos++
+				switch esc := p.src[p.pos]; esc {
+				case 'n':
+					b.WriteByte('\n')
+				case 't':
+					b.WriteByte('\t')
+				case 'r':
+					b.WriteByte('\r')
+				case '"', '\\', '$':
+					b.WriteByte(esc)
+				default:
+					b.WriteByte('\\')
+					b.WriteByte(esc)
+				}
+			case ch == '$':
+				b.WriteString(p.expand())
+				p.pos-- // expand leaves p.pos after the reference
+			default:
+				if ch == '\n' {
+					p.line++
+				}
+				b.WriteByte(ch)
+			}
+		}
+	}
+
+	end := strings.IndexByte(p.src[p.pos:], '\n')
+	if end < 0 {
+		end = len(p.src) - p.pos
+	}
+	raw := p.src[p.pos : p.pos+end]
+	if i := strings.Index(raw, " #"); i >= 0 {
+		raw = raw[:i]
+	}
+	if i := strings.Index(raw, "\t#"); i >= 0 {
+		raw = raw[:i]
+	}
+	raw = strings.TrimSpace(raw)
+	p.pos += end
+
+	var b strings.Builder
+	for i := 0; i < len(raw); i++ {
+		if raw[i] != '$' {
+			b.WriteByte(raw[i])
+			continue
+		}
+		sub := &envParser{src: raw, pos: i, doc: p.doc, lookup: p.lookup}
+		b.WriteString(sub.expand())
+		p.expanded = p.expanded || sub.expanded
+		i = sub.pos - 1
+	}
+	return b.String(), nil
+}
+
+// expand reads a reference starting at the "$" at p.pos, leaving p.pos
+// after it, and returns its value, or the reference itself when it is not
+// expanded.
+func (p *envParser) expand() string {
+	start := p.pos
+	p.pos++
+	if p.lookup == nil || !strings.HasPrefix(p.src[p.pos:], "{") || strings.HasPrefix(p.src[p.pos:], "{{") {
+		return "$"
+	}
+	end := strings.IndexByte(p.src[p.pos:], '}')
+	if end < 0 {
+		return "$"
+	}
+	name := p.src[p.pos+1 : p.pos+end]
+	if !isEnvName(name) {
+		return "$"
+	}
+	p.pos += end + 1
+	if v, ok := p.doc.Get(name); ok {
+		p.expanded = true
+		return v
+	}
+	if v, ok := p.lookup(name); ok {
+		p.expanded = true
+		return v
+	}
+	return p.src[start:p.pos]
+}
+
+// endOfLine skips the rest of the line after a quoted value, which may only
+// hold a comment.
+func (p *envParser) endOfLine() error {
+	end := strings.IndexByte(p.src[p.pos:], '\n')
+	if end < 0 {
+		end = len(p.src) - p.pos
+	}
+	rest := strings.TrimSpace(p.src[p.pos : p.pos+end])
+	if rest != "" && !strings.HasPrefix(rest, "#") {
+		return p.errorf("unexpected %q after closing quote", rest)
+	}
+	p.pos += end
+	return nil
+}
+
+func isEnvName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, r := range name {
+		switch {
+		case r == '_', r >= 'A' && r <= 'Z', r >= 'a' && r <= 'z':
+		case i > 0 && r >= '0' && r <= '9':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// FormatEnv renders env vars the way Dokploy stores them: a single string of
+// KEY=VALUE lines, sorted so repeated runs produce identical output.
+func FormatEnv(envVars map[string]string) string {
+	return EnvFromMap(envVars).String()
+}
+
+// quoteEnvValue double-quotes v if ParseEnv would otherwise read it back
+// differently. "$" is not escaped, so references left unexpanded are still
+// resolved by Dokploy.
+func quoteEnvValue(v string) string {
+	if !strings.ContainsAny(v, "\n\r") && !strings.HasPrefix(v, `"`) && !strings.HasPrefix(v, "'") &&
+		!strings.Contains(v, " #") && !strings.Contains(v, "\t#") && strings.TrimSpace(v) == v {
+		return v
+	}
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`)
+	return `"` + r.Replace(v) + `"`
+}
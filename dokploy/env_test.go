@@ -0,0 +1,177 @@
+package dokploy
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseEnv_Syntax(t *testing.T) {
+	t.Helper()
+
+	src := `# database
+export DB_HOST=db.internal   # inline comment
+DB_URL=postgres://${DB_HOST}:5432/${DB_NAME}
+SINGLE='${DB_HOST} # kept'
+DOUBLE="a \"quoted\"\tvalue\n"
+MULTI="line 1
+line 2"
+HASH=abc#def
+EMPTY=
+REF=${{project.SECRET}}
+HOME_DIR=${HOME}
+GREETING="hi ${DB_HOST}
+bye $5"
+DB_HOST=override
+`
+	lookup := func(name string) (string, bool) {
+		if name == "HOME" {
+			return "/home/ops", true
+		}
+		return "", false
+	}
+
+	got, err := ParseEnv(src, lookup)
+	if err != nil {
+		t.Fatalf("ParseEnv error: %v", err)
+	}
+	want := map[string]string{
+		"DB_HOST":  "override",
+		"DB_URL":   "postgres://db.internal:5432/${DB_NAME}",
+		"SINGLE":   "${DB_HOST} # kept",
+		"DOUBLE":   "a \"quoted\"\tvalue\n",
+		"MULTI":    "line 1\nline 2",
+		"HASH":     "abc#def",
+		"EMPTY":    "",
+		"REF":      "${{project.SECRET}}",
+		"HOME_DIR": "/home/ops",
+		"GREETING": "hi db.internal\nbye $5",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseEnv = %v, want %v", got, want)
+	}
+}
+
+func TestParseEnv_NilLookupDoesNotExpand(t *testing.T) {
+	t.Helper()
+
+	got, err := ParseEnv("A=1\nB=${A}\n", nil)
+	if err != nil {
+		t.Fatalf("ParseEnv error: %v", err)
+	}
+	if got["B"] != "${A}" {
+		t.Errorf("B = %q, want %q", got["B"], "${A}")
+	}
+}
+
+func TestParseEnv_Errors(t *testing.T) {
+	t.Helper()
+
+	cases := map[string]string{
+		"A=1\nnot a var\n":     "line 2: expected KEY=VALUE",
+		"A=\"open\nB=2\n":      "A: line 1: unterminated double quote",
+		"A='x' trailing\n":     `A: line 1: unexpected "trailing" after closing quote`,
+		"MY KEY=1\n":           `line 1: invalid key "MY KEY"`,
+		"A=1\n\nB='unclosed\n": "B: line 3: unterminated single quote",
+	}
+	for src, want := range cases {
+		_, err := ParseEnv(src, nil)
+		if err == nil || err.Error() != want {
+			t.Errorf("ParseEnv(%q) error = %v, want %q", src, err, want)
+		}
+	}
+}
+
+func TestFormatEnv_RoundTrips(t *testing.T) {
+	t.Helper()
+
+	vars := map[string]string{
+		"PLAIN":  "value with spaces",
+		"MULTI":  "line 1\nline 2",
+		"QUOTED": `"starts with a quote`,
+		"PAD":    " padded ",
+		"HASH":   "a #b",
+		"BACK":   `C:\path`,
+		"REF":    "${{project.SECRET}}",
+	}
+	s := FormatEnv(vars)
+	got, err := ParseEnv(s, nil)
+	if err != nil {
+		t.Fatalf("ParseEnv(%q) error: %v", s, err)
+	}
+	if !reflect.DeepEqual(got, vars) {
+		t.Errorf("ParseEnv(FormatEnv(vars)) = %v, want %v", got, vars)
+	}
+}
+
+func TestEnvDocument_RoundTripsUnchanged(t *testing.T) {
+	t.Helper()
+
+	src := "# database\nexport DB_HOST=db   # primary\n\nDB_HOST=replica\nMULTI=\"a\nb\"\nRAW='x'"
+	doc, err := ParseEnvDocument(src, nil)
+	if err != nil {
+		t.Fatalf("ParseEnvDocument error: %v", err)
+	}
+	if got := doc.String(); got != src {
+		t.Errorf("String() = %q, want %q", got, src)
+	}
+	if got, want := doc.Keys(), []string{"DB_HOST", "MULTI", "RAW"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Keys() = %v, want %v", got, want)
+	}
+	if v, _ := doc.Get("DB_HOST"); v != "replica" {
+		t.Errorf("Get(DB_HOST) = %q, want the last definition %q", v, "replica")
+	}
+}
+
+func TestEnvDocument_SetUnsetMerge(t *testing.T) {
+	t.Helper()
+
+	doc, err := ParseEnvDocument("# app\nB=2 # keep\nA=1\nC=3\nA=old", nil)
+	if err != nil {
+		t.Fatalf("ParseEnvDocument error: %v", err)
+	}
+	doc.Set("A", "new")
+	doc.Set("B", "2")
+	doc.Set("D", "two words #x")
+	if !doc.Unset("C") || doc.Unset("MISSING") {
+		t.Errorf("Unset reported the wrong result")
+	}
+
+	other := &EnvDocument{}
+	other.Append("B", "20")
+	other.Append("E", "5")
+	doc.Merge(other)
+
+	want := "# app\nB=20\nA=1\nA=new\nD=\"two words #x\"\nE=5"
+	if got := doc.String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestEnvDocument_ConcatKeepsDuplicates(t *testing.T) {
+	t.Helper()
+
+	doc := &EnvDocument{}
+	doc.Append("A", "1")
+	doc.Append("A", "2")
+	more, err := ParseEnvDocument("# more\nB=${A}", func(string) (string, bool) { return "", false })
+	if err != nil {
+		t.Fatalf("ParseEnvDocument error: %v", err)
+	}
+	doc.Concat(more)
+
+	if got, want := doc.String(), "A=1\nA=2\n# more\nB=${A}"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestEnvDocument_ExpandedValuesAreWritten(t *testing.T) {
+	t.Helper()
+
+	doc, err := ParseEnvDocument("HOST=db # comment\nURL=http://${HOST}", func(string) (string, bool) { return "", false })
+	if err != nil {
+		t.Fatalf("ParseEnvDocument error: %v", err)
+	}
+	if got, want := doc.String(), "HOST=db # comment\nURL=http://db"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
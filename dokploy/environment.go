@@ -0,0 +1,98 @@
+package dokploy
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// Environment create: POST /api/environment.create
+// Environment get: GET /api/environment.one?environmentId=...
+// Environment update: POST /api/environment.update
+// Environment remove: POST /api/environment.remove
+// Environment duplicate: POST /api/environment.duplicate
+
+type environmentResponse struct {
+	EnvironmentID string `json:"environmentId"`
+}
+
+// CreateEnvironment calls POST /api/environment.create and returns the ID of
+// the new environment.
+func CreateEnvironment(ctx context.Context, client *Client, projectID, name, description string) (string, error) {
+	payload := map[string]any{
+		"projectId":   projectID,
+		"name":        name,
+		"description": description,
+	}
+	var resp environmentResponse
+	if err := client.do(ctx, http.MethodPost, "/api/environment.create", payload, &resp); err != nil {
+		return "", err
+	}
+	return resp.EnvironmentID, nil
+}
+
+// GetEnvironment calls GET /api/environment.one.
+func GetEnvironment(ctx context.Context, client *Client, id string) (*ProjectEnvironment, error) {
+	q := url.Values{}
+	q.Set("environmentId", id)
+	var out ProjectEnvironment
+	if err := client.do(ctx, http.MethodGet, "/api/environment.one?"+q.Encode(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListEnvironments returns the environments of a project, using the data
+// returned from ListProjects.
+func ListEnvironments(ctx context.Context, client *Client, projectID string) ([]ProjectEnvironment, error) {
+	projects, err := ListProjects(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range projects {
+		if p.ProjectID == projectID {
+			return p.Environments, nil
+		}
+	}
+	return nil, fmt.Errorf("project %q: %w", projectID, ErrProjectNotFound)
+}
+
+// UpdateEnvironment calls POST /api/environment.update, changing only the
+// fields that are non-empty.
+func UpdateEnvironment(ctx context.Context, client *Client, id, name, description string) error {
+	payload := map[string]any{
+		"environmentId": id,
+	}
+	if name != "" {
+		payload["name"] = name
+	}
+	if description != "" {
+		payload["description"] = description
+	}
+	return client.do(ctx, http.MethodPost, "/api/environment.update", payload, nil)
+}
+
+// DeleteEnvironment calls POST /api/environment.remove.
+func DeleteEnvironment(ctx context.Context, client *Client, id string) error {
+	payload := map[string]any{
+		"environmentId": id,
+	}
+	return client.do(ctx, http.MethodPost, "/api/environment.remove", payload, nil)
+}
+
+// DuplicateEnvironment calls POST /api/environment.duplicate, copying an
+// environment and its services under a new name, and returns the ID of the
+// copy.
+func DuplicateEnvironment(ctx context.Context, client *Client, id, name, description string) (string, error) {
+	payload := map[string]any{
+		"environmentId": id,
+		"name":          name,
+		"description":   description,
+	}
+	var resp environmentResponse
+	if err := client.do(ctx, http.MethodPost, "/api/environment.duplicate", payload, &resp); err != nil {
+		return "", err
+	}
+	return resp.EnvironmentID, nil
+}
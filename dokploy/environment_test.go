@@ -0,0 +1,157 @@
+package dokploy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCreateEnvironment_CallsEnvironmentCreate(t *testing.T) {
+	t.Helper()
+
+	var gotPath string
+	var gotBody map[string]any
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		if err := json.NewDecoder(r.Body).Decode(&gotBody); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"environmentId": "env-2", "name": "staging"})
+	}))
+	defer ts.Close()
+
+	client, err := NewClient(ts.URL, "key")
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+
+	id, err := CreateEnvironment(context.Background(), client, "proj-1", "staging", "")
+	if err != nil {
+		t.Fatalf("CreateEnvironment error: %v", err)
+	}
+	if gotPath != "/api/environment.create" {
+		t.Errorf("path = %q, want %q", gotPath, "/api/environment.create")
+	}
+	if gotBody["projectId"] != "proj-1" || gotBody["name"] != "staging" {
+		t.Errorf("body = %v, want projectId proj-1 and name staging", gotBody)
+	}
+	if id != "env-2" {
+		t.Errorf("id = %q, want %q", id, "env-2")
+	}
+}
+
+func TestGetEnvironment_CallsEnvironmentOne(t *testing.T) {
+	t.Helper()
+
+	var gotQuery string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/environment.one" || r.Method != http.MethodGet {
+			t.Errorf("request = %s %s, want GET /api/environment.one", r.Method, r.URL.Path)
+		}
+		gotQuery = r.URL.Query().Get("environmentId")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"environmentId": "env-1",
+			"name":          "production",
+			"projectId":     "proj-1",
+			"compose":       []map[string]any{{"composeId": "cmp-1", "name": "web"}},
+		})
+	}))
+	defer ts.Close()
+
+	client, err := NewClient(ts.URL, "key")
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+
+	env, err := GetEnvironment(context.Background(), client, "env-1")
+	if err != nil {
+		t.Fatalf("GetEnvironment error: %v", err)
+	}
+	if gotQuery != "env-1" {
+		t.Errorf("environmentId = %q, want %q", gotQuery, "env-1")
+	}
+	if env.ProjectID != "proj-1" || len(env.Compose) != 1 || env.Compose[0].ComposeID != "cmp-1" {
+		t.Errorf("env = %+v, want projectId proj-1 with compose cmp-1", env)
+	}
+}
+
+func TestUpdateEnvironment_SendsOnlyChangedFields(t *testing.T) {
+	t.Helper()
+
+	var gotPath string
+	var gotBody map[string]any
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		_ = json.NewDecoder(r.Body).Decode(&gotBody)
+	}))
+	defer ts.Close()
+
+	client, err := NewClient(ts.URL, "key")
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+
+	if err := UpdateEnvironment(context.Background(), client, "env-1", "qa", ""); err != nil {
+		t.Fatalf("UpdateEnvironment error: %v", err)
+	}
+	if gotPath != "/api/environment.update" {
+		t.Errorf("path = %q, want %q", gotPath, "/api/environment.update")
+	}
+	if gotBody["environmentId"] != "env-1" || gotBody["name"] != "qa" {
+		t.Errorf("body = %v, want environmentId env-1 and name qa", gotBody)
+	}
+	if _, ok := gotBody["description"]; ok {
+		t.Errorf("description should be omitted when empty, got %v", gotBody["description"])
+	}
+}
+
+func TestDuplicateEnvironment_ReturnsNewID(t *testing.T) {
+	t.Helper()
+
+	var gotBody map[string]any
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/environment.duplicate" {
+			t.Errorf("path = %q, want %q", r.URL.Path, "/api/environment.duplicate")
+		}
+		_ = json.NewDecoder(r.Body).Decode(&gotBody)
+		_ = json.NewEncoder(w).Encode(map[string]any{"environmentId": "env-3", "name": "preview"})
+	}))
+	defer ts.Close()
+
+	client, err := NewClient(ts.URL, "key")
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+
+	id, err := DuplicateEnvironment(context.Background(), client, "env-1", "preview", "")
+	if err != nil {
+		t.Fatalf("DuplicateEnvironment error: %v", err)
+	}
+	if gotBody["environmentId"] != "env-1" || gotBody["name"] != "preview" {
+		t.Errorf("body = %v, want environmentId env-1 and name preview", gotBody)
+	}
+	if id != "env-3" {
+		t.Errorf("id = %q, want %q", id, "env-3")
+	}
+}
+
+func TestListEnvironments_UnknownProject(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode([]Project{{ProjectID: "proj-1", Name: "shop"}})
+	}))
+	defer ts.Close()
+
+	client, err := NewClient(ts.URL, "key")
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+
+	if _, err := ListEnvironments(context.Background(), client, "proj-2"); !errors.Is(err, ErrProjectNotFound) {
+		t.Errorf("err = %v, want ErrProjectNotFound", err)
+	}
+}
@@ -0,0 +1,145 @@
+package dokploy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// maxErrorBody caps how much of an error response body is read.
+const maxErrorBody = 64 << 10
+
+// APIError is returned by Client when Dokploy responds with a 4xx or 5xx
+// status. Message and Code are taken from the tRPC/OpenAPI error body when
+// Dokploy sends one; otherwise Message is the raw body text.
+type APIError struct {
+	StatusCode int
+	Status     string
+	Method     string
+	Path       string
+	Code       string
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("%s %s: %s", e.Method, e.Path, e.Status)
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	if e.Code != "" {
+		msg += " (" + e.Code + ")"
+	}
+	return msg
+}
+
+// IsNotFound reports whether err is an APIError for a missing resource.
+func IsNotFound(err error) bool {
+	return hasStatusOrCode(err, http.StatusNotFound, "NOT_FOUND")
+}
+
+// IsUnauthorized reports whether err is an APIError caused by a missing or
+// invalid API key.
+func IsUnauthorized(err error) bool {
+	return hasStatusOrCode(err, http.StatusUnauthorized, "UNAUTHORIZED")
+}
+
+// IsForbidden reports whether err is an APIError caused by the API key
+// lacking permission for the request.
+func IsForbidden(err error) bool {
+	return hasStatusOrCode(err, http.StatusForbidden, "FORBIDDEN")
+}
+
+// IsConflict reports whether err is an APIError caused by a conflicting
+// resource, such as a duplicate name.
+func IsConflict(err error) bool {
+	return hasStatusOrCode(err, http.StatusConflict, "CONFLICT")
+}
+
+func hasStatusOrCode(err error, status int, code string) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	return apiErr.StatusCode == status || apiErr.Code == code
+}
+
+// errorBody covers the error shapes Dokploy returns: the OpenAPI adapter's
+// flat {message, code, issues} and tRPC's {error: {message, data: {code}}},
+// optionally wrapped in a superjson {json: ...} envelope.
+type errorBody struct {
+	Message string `json:"message"`
+	Code    string `json:"code"`
+	Issues  []struct {
+		Message string `json:"message"`
+		Path    []any  `json:"path"`
+	} `json:"issues"`
+	Error *struct {
+		Message string `json:"message"`
+		Data    struct {
+			Code string `json:"code"`
+		} `json:"data"`
+		JSON *struct {
+			Message string `json:"message"`
+			Data    struct {
+				Code string `json:"code"`
+			} `json:"data"`
+		} `json:"json"`
+	} `json:"error"`
+}
+
+// newAPIError builds an APIError from a failed response, consuming its body.
+func newAPIError(method, path string, resp *http.Response) *APIError {
+	if i := strings.IndexByte(path, '?'); i >= 0 {
+		path = path[:i]
+	}
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		Method:     method,
+		Path:       path,
+	}
+
+	raw, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	var body errorBody
+	if err := json.Unmarshal(raw, &body); err != nil {
+		apiErr.Message = strings.TrimSpace(string(raw))
+		return apiErr
+	}
+
+	switch {
+	case body.Error != nil && body.Error.JSON != nil:
+		apiErr.Message = body.Error.JSON.Message
+		apiErr.Code = body.Error.JSON.Data.Code
+	case body.Error != nil:
+		apiErr.Message = body.Error.Message
+		apiErr.Code = body.Error.Data.Code
+	default:
+		apiErr.Message = body.Message
+		apiErr.Code = body.Code
+	}
+
+	// Validation failures carry one issue per invalid field; the top-level
+	// message alone doesn't say which.
+	var issues []string
+	for _, is := range body.Issues {
+		if len(is.Path) == 0 {
+			issues = append(issues, is.Message)
+			continue
+		}
+		var parts []string
+		for _, p := range is.Path {
+			parts = append(parts, fmt.Sprint(p))
+		}
+		issues = append(issues, strings.Join(parts, ".")+": "+is.Message)
+	}
+	if len(issues) > 0 {
+		if apiErr.Message != "" {
+			issues = append([]string{apiErr.Message}, issues...)
+		}
+		apiErr.Message = strings.Join(issues, "; ")
+	}
+	return apiErr
+}
@@ -0,0 +1,111 @@
+package dokploy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientDo_ReturnsAPIError(t *testing.T) {
+	cases := []struct {
+		name        string
+		status      int
+		body        string
+		wantCode    string
+		wantMessage string
+	}{
+		{
+			name:        "openapi",
+			status:      http.StatusBadRequest,
+			body:        `{"message":"Input validation failed","code":"BAD_REQUEST","issues":[{"message":"Required","path":["name"]}]}`,
+			wantCode:    "BAD_REQUEST",
+			wantMessage: "Input validation failed; name: Required",
+		},
+		{
+			name:        "trpc",
+			status:      http.StatusNotFound,
+			body:        `{"error":{"message":"Compose not found","code":-32004,"data":{"code":"NOT_FOUND","httpStatus":404}}}`,
+			wantCode:    "NOT_FOUND",
+			wantMessage: "Compose not found",
+		},
+		{
+			name:        "superjson",
+			status:      http.StatusConflict,
+			body:        `{"error":{"json":{"message":"Domain already exists","data":{"code":"CONFLICT"}}}}`,
+			wantCode:    "CONFLICT",
+			wantMessage: "Domain already exists",
+		},
+		{
+			name:        "plain text",
+			status:      http.StatusBadGateway,
+			body:        "upstream unavailable\n",
+			wantMessage: "upstream unavailable",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tc.status)
+				_, _ = w.Write([]byte(tc.body))
+			}))
+			defer ts.Close()
+
+			client, err := NewClient(ts.URL, "key", WithRetryPolicy(RetryPolicy{}))
+			if err != nil {
+				t.Fatalf("NewClient error: %v", err)
+			}
+
+			err = client.do(context.Background(), http.MethodGet, "/api/compose.one?composeId=cmp-1", nil, nil)
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("err = %v (%T), want *APIError", err, err)
+			}
+			if apiErr.StatusCode != tc.status {
+				t.Errorf("StatusCode = %d, want %d", apiErr.StatusCode, tc.status)
+			}
+			if apiErr.Method != http.MethodGet || apiErr.Path != "/api/compose.one" {
+				t.Errorf("request = %s %s, want GET /api/compose.one", apiErr.Method, apiErr.Path)
+			}
+			if apiErr.Code != tc.wantCode {
+				t.Errorf("Code = %q, want %q", apiErr.Code, tc.wantCode)
+			}
+			if apiErr.Message != tc.wantMessage {
+				t.Errorf("Message = %q, want %q", apiErr.Message, tc.wantMessage)
+			}
+		})
+	}
+}
+
+func TestAPIErrorHelpers(t *testing.T) {
+	wrapped := fmt.Errorf("compose %q: %w", "web", &APIError{StatusCode: http.StatusNotFound})
+	if !IsNotFound(wrapped) {
+		t.Errorf("IsNotFound(wrapped 404) = false, want true")
+	}
+	if IsConflict(wrapped) {
+		t.Errorf("IsConflict(wrapped 404) = true, want false")
+	}
+	if !IsUnauthorized(&APIError{StatusCode: http.StatusBadRequest, Code: "UNAUTHORIZED"}) {
+		t.Errorf("IsUnauthorized(code UNAUTHORIZED) = false, want true")
+	}
+	if !IsForbidden(&APIError{StatusCode: http.StatusForbidden}) {
+		t.Errorf("IsForbidden(403) = false, want true")
+	}
+	if !IsConflict(&APIError{StatusCode: http.StatusConflict}) {
+		t.Errorf("IsConflict(409) = false, want true")
+	}
+	if IsNotFound(errors.New("project not found")) {
+		t.Errorf("IsNotFound(plain error) = true, want false")
+	}
+}
+
+func TestAPIError_Error(t *testing.T) {
+	err := &APIError{Status: "400 Bad Request", Method: "POST", Path: "/api/compose.create", Code: "BAD_REQUEST", Message: "name: Required"}
+	want := "POST /api/compose.create: 400 Bad Request: name: Required (BAD_REQUEST)"
+	if got := err.Error(); got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
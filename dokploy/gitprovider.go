@@ -0,0 +1,178 @@
+package dokploy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Git providers: GET /api/gitProvider.getAll
+// GitHub repositories: GET /api/github.getGithubRepositories?githubId=...
+// GitLab repositories: GET /api/gitlab.getGitlabRepositories?gitlabId=...
+
+// Git provider types, which are also the compose source types that use them.
+const (
+	ProviderGitHub    = "github"
+	ProviderGitLab    = "gitlab"
+	ProviderBitbucket = "bitbucket"
+	ProviderGitea     = "gitea"
+)
+
+// ErrGitProviderNotFound is returned by FindGitProvider when no provider
+// matches.
+var ErrGitProviderNotFound = errors.New("git provider not found")
+
+// GitProvider is a GitHub, GitLab, Bitbucket or Gitea account connected to
+// Dokploy. ID is the gitProviderId; ProviderID is the ID of the account
+// itself (githubId, gitlabId, ...), which is what compose and application
+// sources refer to.
+type GitProvider struct {
+	ID         string `json:"gitProviderId"`
+	Name       string `json:"name"`
+	Type       string `json:"providerType"`
+	ProviderID string `json:"providerId"`
+	CreatedAt  string `json:"createdAt"`
+}
+
+type gitProviderResponse struct {
+	GitProviderID string `json:"gitProviderId"`
+	Name          string `json:"name"`
+	ProviderType  string `json:"providerType"`
+	CreatedAt     string `json:"createdAt"`
+	GitHub        *struct {
+		ID string `json:"githubId"`
+	} `json:"github"`
+	GitLab *struct {
+		ID string `json:"gitlabId"`
+	} `json:"gitlab"`
+	Bitbucket *struct {
+		ID string `json:"bitbucketId"`
+	} `json:"bitbucket"`
+	Gitea *struct {
+		ID string `json:"giteaId"`
+	} `json:"gitea"`
+}
+
+// ListGitProviders calls GET /api/gitProvider.getAll.
+func ListGitProviders(ctx context.Context, client *Client) ([]GitProvider, error) {
+	var resp []gitProviderResponse
+	if err := client.do(ctx, http.MethodGet, "/api/gitProvider.getAll", nil, &resp); err != nil {
+		return nil, err
+	}
+	providers := make([]GitProvider, 0, len(resp))
+	for _, r := range resp {
+		p := GitProvider{ID: r.GitProviderID, Name: r.Name, Type: r.ProviderType, CreatedAt: r.CreatedAt}
+		switch {
+		case r.GitHub != nil:
+			p.ProviderID = r.GitHub.ID
+		case r.GitLab != nil:
+			p.ProviderID = r.GitLab.ID
+		case r.Bitbucket != nil:
+			p.ProviderID = r.Bitbucket.ID
+		case r.Gitea != nil:
+			p.ProviderID = r.Gitea.ID
+		}
+		providers = append(providers, p)
+	}
+	return providers, nil
+}
+
+// FindGitProvider returns the provider of the given type whose name,
+// gitProviderId or provider ID is nameOrID. An empty nameOrID matches the
+// only provider of that type, if there is exactly one.
+func FindGitProvider(ctx context.Context, client *Client, providerType, nameOrID string) (*GitProvider, error) {
+	providers, err := ListGitProviders(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	var matches []GitProvider
+	for _, p := range providers {
+		if p.Type != providerType {
+			continue
+		}
+		if nameOrID == "" || p.Name == nameOrID || p.ID == nameOrID || p.ProviderID == nameOrID {
+			matches = append(matches, p)
+		}
+	}
+	switch {
+	case len(matches) == 1:
+		return &matches[0], nil
+	case len(matches) == 0 && nameOrID == "":
+		return nil, fmt.Errorf("%w: no %s provider is connected to Dokploy", ErrGitProviderNotFound, providerType)
+	case len(matches) == 0:
+		return nil, fmt.Errorf("%w: no %s provider named %q", ErrGitProviderNotFound, providerType, nameOrID)
+	case nameOrID == "":
+		return nil, fmt.Errorf("%d %s providers are connected; choose one by name or ID", len(matches), providerType)
+	}
+	return nil, fmt.Errorf("%d %s providers are named %q; choose one by ID", len(matches), providerType, nameOrID)
+}
+
+// ProviderRepository is a repository a git provider gives access to.
+// FullName is the path used to clone it, such as "owner/repo" or, on
+// GitLab, "group/subgroup/repo". ID is only set for GitLab projects.
+type ProviderRepository struct {
+	ID       string `json:"id,omitempty"`
+	Name     string `json:"name"`
+	Owner    string `json:"owner"`
+	FullName string `json:"fullName"`
+}
+
+// ListProviderRepositories lists the repositories of a GitHub or GitLab
+// provider, given its provider ID (githubId or gitlabId).
+func ListProviderRepositories(ctx context.Context, client *Client, providerType, providerID string) ([]ProviderRepository, error) {
+	switch providerType {
+	case ProviderGitHub:
+		q := url.Values{}
+		q.Set("githubId", providerID)
+		var resp []struct {
+			Name     string `json:"name"`
+			FullName string `json:"full_name"`
+			Owner    struct {
+				Login string `json:"login"`
+			} `json:"owner"`
+		}
+		if err := client.do(ctx, http.MethodGet, "/api/github.getGithubRepositories?"+q.Encode(), nil, &resp); err != nil {
+			return nil, err
+		}
+		repos := make([]ProviderRepository, 0, len(resp))
+		for _, r := range resp {
+			repos = append(repos, ProviderRepository{Name: r.Name, Owner: r.Owner.Login, FullName: r.FullName})
+		}
+		return repos, nil
+	case ProviderGitLab:
+		q := url.Values{}
+		q.Set("gitlabId", providerID)
+		var resp []struct {
+			ID    int    `json:"id"`
+			Name  string `json:"name"`
+			URL   string `json:"url"` // path with namespace
+			Owner struct {
+				Username string `json:"username"`
+			} `json:"owner"`
+		}
+		if err := client.do(ctx, http.MethodGet, "/api/gitlab.getGitlabRepositories?"+q.Encode(), nil, &resp); err != nil {
+			return nil, err
+		}
+		repos := make([]ProviderRepository, 0, len(resp))
+		for _, r := range resp {
+			repos = append(repos, ProviderRepository{ID: strconv.Itoa(r.ID), Name: r.Name, Owner: r.Owner.Username, FullName: r.URL})
+		}
+		return repos, nil
+	}
+	return nil, fmt.Errorf("listing repositories of %s providers is not supported", providerType)
+}
+
+// splitRepository splits "owner/repo" into its owner and name. On GitLab
+// the owner may be a nested group, such as "group/subgroup".
+func splitRepository(fullName string) (owner, name string) {
+	fullName = strings.Trim(fullName, "/")
+	i := strings.LastIndex(fullName, "/")
+	if i < 0 {
+		return "", fullName
+	}
+	return fullName[:i], fullName[i+1:]
+}
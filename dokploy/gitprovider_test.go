@@ -0,0 +1,98 @@
+package dokploy
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func gitProvidersServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/gitProvider.getAll":
+			_, _ = w.Write([]byte(`[
+				{"gitProviderId": "gp-1", "name": "work-gh", "providerType": "github", "github": {"githubId": "gh-1"}, "gitlab": null},
+				{"gitProviderId": "gp-2", "name": "gitlab", "providerType": "gitlab", "gitlab": {"gitlabId": "gl-1"}},
+				{"gitProviderId": "gp-3", "name": "oss-gh", "providerType": "github", "github": {"githubId": "gh-2"}}
+			]`))
+		case "/api/gitlab.getGitlabRepositories":
+			if got := r.URL.Query().Get("gitlabId"); got != "gl-1" {
+				t.Errorf("gitlabId = %q, want %q", got, "gl-1")
+			}
+			_, _ = w.Write([]byte(`[{"id": 42, "name": "api", "url": "team/backend/api", "owner": {"username": "team"}}]`))
+		default:
+			t.Errorf("unexpected request %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestListGitProviders_ReadsProviderIDs(t *testing.T) {
+	t.Helper()
+
+	ts := gitProvidersServer(t)
+	defer ts.Close()
+	client, err := NewClient(ts.URL, "key")
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+
+	providers, err := ListGitProviders(context.Background(), client)
+	if err != nil {
+		t.Fatalf("ListGitProviders error: %v", err)
+	}
+	if len(providers) != 3 {
+		t.Fatalf("len(providers) = %d, want 3", len(providers))
+	}
+	if p := providers[1]; p.ID != "gp-2" || p.Type != ProviderGitLab || p.ProviderID != "gl-1" {
+		t.Errorf("providers[1] = %+v, want gp-2 gitlab gl-1", p)
+	}
+}
+
+func TestFindGitProvider(t *testing.T) {
+	t.Helper()
+
+	ts := gitProvidersServer(t)
+	defer ts.Close()
+	client, err := NewClient(ts.URL, "key")
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	ctx := context.Background()
+
+	if p, err := FindGitProvider(ctx, client, ProviderGitLab, ""); err != nil || p.ProviderID != "gl-1" {
+		t.Errorf("FindGitProvider(gitlab, \"\") = %+v, %v; want the only gitlab provider", p, err)
+	}
+	if p, err := FindGitProvider(ctx, client, ProviderGitHub, "oss-gh"); err != nil || p.ProviderID != "gh-2" {
+		t.Errorf("FindGitProvider(github, oss-gh) = %+v, %v; want gh-2", p, err)
+	}
+	if _, err := FindGitProvider(ctx, client, ProviderGitHub, ""); err == nil {
+		t.Errorf("FindGitProvider(github, \"\") error = nil, want an error for two providers")
+	}
+	if _, err := FindGitProvider(ctx, client, ProviderGitea, ""); !errors.Is(err, ErrGitProviderNotFound) {
+		t.Errorf("FindGitProvider(gitea) error = %v, want ErrGitProviderNotFound", err)
+	}
+}
+
+func TestListProviderRepositories_GitLab(t *testing.T) {
+	t.Helper()
+
+	ts := gitProvidersServer(t)
+	defer ts.Close()
+	client, err := NewClient(ts.URL, "key")
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+
+	repos, err := ListProviderRepositories(context.Background(), client, ProviderGitLab, "gl-1")
+	if err != nil {
+		t.Fatalf("ListProviderRepositories error: %v", err)
+	}
+	if len(repos) != 1 || repos[0].ID != "42" || repos[0].FullName != "team/backend/api" {
+		t.Errorf("repos = %+v, want project 42 team/backend/api", repos)
+	}
+}
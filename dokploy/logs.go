@@ -0,0 +1,121 @@
+package dokploy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gorilla/websocket"
+)
+
+// Deployment logs: websocket /listen-deployment?logPath=...
+//
+// Dokploy tails the deployment's log file over this socket and keeps it
+// open after the deployment ends, so streaming only stops when ctx is done.
+
+// StreamDeploymentLogs connects to Dokploy's deployment log websocket and
+// copies the log at logPath (see Deployment.LogPath) to w as it is written.
+// It returns nil when ctx is cancelled or the server closes the connection
+// normally.
+func StreamDeploymentLogs(ctx context.Context, client *Client, logPath string, w io.Writer) error {
+	if logPath == "" {
+		return errors.New("deployment has no log path")
+	}
+	wsURL, err := client.websocketURL("/listen-deployment", url.Values{"logPath": {logPath}})
+	if err != nil {
+		return err
+	}
+
+	dialer := client.websocketDialer()
+	conn, resp, err := dialer.DialContext(ctx, wsURL, client.websocketHeader(wsURL))
+	if err != nil {
+		if resp != nil && resp.StatusCode >= 400 {
+			return newAPIError(http.MethodGet, "/listen-deployment", resp)
+		}
+		return fmt.Errorf("connect to deployment logs: %w", err)
+	}
+	defer conn.Close()
+
+	// ReadMessage blocks without honouring ctx; closing the connection is
+	// what unblocks it.
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	for {
+		_, msg, err := conn.ReadMessage()
+		if err != nil {
+			if ctx.Err() != nil || websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				return nil
+			}
+			return fmt.Errorf("read deployment logs: %w", err)
+		}
+		if _, err := w.Write(msg); err != nil {
+			return err
+		}
+	}
+}
+
+// websocketURL turns an endpoint path into a ws:// or wss:// URL on the
+// client's base URL.
+func (c *Client) websocketURL(path string, q url.Values) (string, error) {
+	u, err := url.Parse(c.baseURL + path)
+	if err != nil {
+		return "", err
+	}
+	switch u.Scheme {
+	case "https":
+		u.Scheme = "wss"
+	case "http":
+		u.Scheme = "ws"
+	default:
+		return "", fmt.Errorf("unsupported url scheme %q", u.Scheme)
+	}
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+// websocketDialer returns a dialer that connects the way the client's
+// requests do: through the proxy, TLS config and dialer of its transport
+// when that is an *http.Transport, as set by WithHTTPClient or
+// WithTransport, and otherwise through the proxy from the environment.
+func (c *Client) websocketDialer() *websocket.Dialer {
+	d := &websocket.Dialer{
+		Proxy:            http.ProxyFromEnvironment,
+		HandshakeTimeout: c.httpClient.Timeout,
+		TLSClientConfig:  c.tlsConfig,
+	}
+	rt := c.httpClient.Transport
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	if t, ok := rt.(*http.Transport); ok {
+		d.Proxy = t.Proxy
+		d.NetDialContext = t.DialContext
+		if t.TLSClientConfig != nil {
+			d.TLSClientConfig = t.TLSClientConfig.Clone()
+		}
+	}
+	return d
+}
+
+// websocketHeader returns the headers for a websocket handshake: the same
+// API key, user agent and request hooks as regular requests.
+func (c *Client) websocketHeader(wsURL string) http.Header {
+	httpURL := "http" + strings.TrimPrefix(wsURL, "ws")
+	req, err := http.NewRequest(http.MethodGet, httpURL, nil)
+	if err != nil {
+		return http.Header{"X-Api-Key": {c.apiKey}}
+	}
+	req.Header.Set("x-api-key", c.apiKey)
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+	for _, hook := range c.hooks {
+		hook(req)
+	}
+	return req.Header
+}
@@ -0,0 +1,128 @@
+package dokploy
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestStreamDeploymentLogs_CopiesMessages(t *testing.T) {
+	t.Helper()
+
+	var gotPath, gotLogPath, gotAPIKey string
+	upgrader := websocket.Upgrader{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotLogPath = r.URL.Query().Get("logPath")
+		gotAPIKey = r.Header.Get("x-api-key")
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_ = conn.WriteMessage(websocket.TextMessage, []byte("Building web\n"))
+		_ = conn.WriteMessage(websocket.TextMessage, []byte("Done\n"))
+		_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+	}))
+	defer ts.Close()
+
+	client, err := NewClient(ts.URL, "key")
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := StreamDeploymentLogs(context.Background(), client, "/etc/dokploy/logs/web/web-1.log", &buf); err != nil {
+		t.Fatalf("StreamDeploymentLogs error: %v", err)
+	}
+	if gotPath != "/listen-deployment" {
+		t.Errorf("path = %q, want %q", gotPath, "/listen-deployment")
+	}
+	if gotLogPath != "/etc/dokploy/logs/web/web-1.log" {
+		t.Errorf("logPath = %q, want %q", gotLogPath, "/etc/dokploy/logs/web/web-1.log")
+	}
+	if gotAPIKey != "key" {
+		t.Errorf("x-api-key = %q, want %q", gotAPIKey, "key")
+	}
+	if buf.String() != "Building web\nDone\n" {
+		t.Errorf("output = %q, want %q", buf.String(), "Building web\nDone\n")
+	}
+}
+
+func TestStreamDeploymentLogs_StopsWhenContextDone(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_ = conn.WriteMessage(websocket.TextMessage, []byte("tailing\n"))
+		// Like Dokploy's tail -f, never close the stream.
+		_, _, _ = conn.ReadMessage()
+	}))
+	defer ts.Close()
+
+	client, err := NewClient(ts.URL, "key")
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	var buf bytes.Buffer
+	if err := StreamDeploymentLogs(ctx, client, "/logs/web.log", &buf); err != nil {
+		t.Fatalf("StreamDeploymentLogs error: %v", err)
+	}
+	if buf.String() != "tailing\n" {
+		t.Errorf("output = %q, want %q", buf.String(), "tailing\n")
+	}
+}
+
+func TestStreamDeploymentLogs_HandshakeRejected(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer ts.Close()
+
+	client, err := NewClient(ts.URL, "bad-key")
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+
+	err = StreamDeploymentLogs(context.Background(), client, "/logs/web.log", &bytes.Buffer{})
+	if !IsUnauthorized(err) {
+		t.Fatalf("err = %v, want unauthorized APIError", err)
+	}
+}
+
+func TestWebsocketDialer_UsesClientTransport(t *testing.T) {
+	t.Helper()
+
+	proxyURL, _ := url.Parse("http://proxy.internal:3128")
+	tlsConfig := &tls.Config{ServerName: "dokploy.internal"}
+	transport := &http.Transport{Proxy: http.ProxyURL(proxyURL), TLSClientConfig: tlsConfig}
+	client, err := NewClient("https://dokploy.example.com", "key", WithHTTPClient(&http.Client{Transport: transport}))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+
+	d := client.websocketDialer()
+	if d.Proxy == nil {
+		t.Fatalf("dialer has no proxy, want the transport's")
+	}
+	req, _ := http.NewRequest(http.MethodGet, "https://dokploy.example.com/listen-deployment", nil)
+	if got, err := d.Proxy(req); err != nil || got.String() != proxyURL.String() {
+		t.Errorf("dialer proxy = %v, %v; want %v", got, err, proxyURL)
+	}
+	if d.TLSClientConfig == nil || d.TLSClientConfig.ServerName != "dokploy.internal" {
+		t.Errorf("dialer TLS config = %+v, want the transport's", d.TLSClientConfig)
+	}
+}
@@ -0,0 +1,66 @@
+package dokploy
+
+import (
+	"crypto/tls"
+	"log"
+	"net/http"
+	"time"
+)
+
+// WithHTTPClient makes the client send requests through hc instead of its
+// own 30-second-timeout http.Client. hc is copied, so options such as
+// WithTimeout never modify the caller's client.
+func WithHTTPClient(hc *http.Client) ClientOption {
+	return func(c *Client) {
+		c.baseHTTPClient = hc
+	}
+}
+
+// WithTimeout sets the timeout of each HTTP attempt. Retries get a fresh
+// timeout; use the request context to bound the whole call.
+func WithTimeout(d time.Duration) ClientOption {
+	return func(c *Client) {
+		c.timeout = &d
+	}
+}
+
+// WithTransport sets the http.RoundTripper used to send requests, for
+// example to route through a proxy or to record traffic in tests.
+func WithTransport(rt http.RoundTripper) ClientOption {
+	return func(c *Client) {
+		c.transport = rt
+	}
+}
+
+// WithTLSConfig sets the TLS configuration, for example to trust a private
+// CA bundle. It applies to the default transport or to one set with
+// WithTransport or WithHTTPClient, which must then be an *http.Transport.
+func WithTLSConfig(cfg *tls.Config) ClientOption {
+	return func(c *Client) {
+		c.tlsConfig = cfg
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with every request.
+func WithUserAgent(ua string) ClientOption {
+	return func(c *Client) {
+		c.userAgent = ua
+	}
+}
+
+// WithRequestHook registers fn to be called with every outgoing request,
+// including retries, just before it is sent. Hooks run in the order they
+// were registered and may add headers or inspect the request.
+func WithRequestHook(fn func(*http.Request)) ClientOption {
+	return func(c *Client) {
+		c.hooks = append(c.hooks, fn)
+	}
+}
+
+// WithLogger logs every request attempt and retry to l. The API key is never
+// logged.
+func WithLogger(l *log.Logger) ClientOption {
+	return func(c *Client) {
+		c.logger = l
+	}
+}
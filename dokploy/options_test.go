@@ -0,0 +1,144 @@
+package dokploy
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+func TestNewClient_UserAgentAndRequestHooks(t *testing.T) {
+	t.Helper()
+
+	var gotUA, gotTrace string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotUA = r.Header.Get("User-Agent")
+		gotTrace = r.Header.Get("X-Trace")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	var hookOrder []string
+	client, err := NewClient(ts.URL, "key",
+		WithUserAgent("my-tool/1.0"),
+		WithRequestHook(func(r *http.Request) {
+			hookOrder = append(hookOrder, "first")
+			r.Header.Set("X-Trace", "abc")
+		}),
+		WithRequestHook(func(r *http.Request) { hookOrder = append(hookOrder, "second") }),
+	)
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+
+	if err := client.do(context.Background(), http.MethodGet, "/api/project.all", nil, nil); err != nil {
+		t.Fatalf("do error: %v", err)
+	}
+	if gotUA != "my-tool/1.0" {
+		t.Errorf("User-Agent = %q, want %q", gotUA, "my-tool/1.0")
+	}
+	if gotTrace != "abc" {
+		t.Errorf("X-Trace = %q, want %q", gotTrace, "abc")
+	}
+	if strings.Join(hookOrder, ",") != "first,second" {
+		t.Errorf("hook order = %v, want [first second]", hookOrder)
+	}
+}
+
+func TestNewClient_HTTPClientIsCopied(t *testing.T) {
+	hc := &http.Client{Timeout: time.Minute}
+	client, err := NewClient("https://dokploy.example.com", "key", WithHTTPClient(hc), WithTimeout(5*time.Second))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	if client.httpClient.Timeout != 5*time.Second {
+		t.Errorf("client timeout = %v, want 5s", client.httpClient.Timeout)
+	}
+	if hc.Timeout != time.Minute {
+		t.Errorf("caller's http.Client timeout changed to %v", hc.Timeout)
+	}
+}
+
+func TestNewClient_WithTransport(t *testing.T) {
+	var gotURL string
+	rt := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		gotURL = r.URL.String()
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Header: http.Header{}}, nil
+	})
+
+	client, err := NewClient("https://dokploy.example.com", "key", WithTransport(rt))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	if err := client.do(context.Background(), http.MethodGet, "/api/project.all", nil, nil); err != nil {
+		t.Fatalf("do error: %v", err)
+	}
+	if gotURL != "https://dokploy.example.com/api/project.all" {
+		t.Errorf("url = %q, want %q", gotURL, "https://dokploy.example.com/api/project.all")
+	}
+}
+
+func TestNewClient_WithTLSConfig(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	// Without the server's CA the handshake fails.
+	plain, err := NewClient(ts.URL, "key", WithRetryPolicy(RetryPolicy{}))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	if err := plain.do(context.Background(), http.MethodGet, "/", nil, nil); err == nil {
+		t.Fatalf("expected TLS verification error, got nil")
+	}
+
+	pool := x509.NewCertPool()
+	pool.AddCert(ts.Certificate())
+	client, err := NewClient(ts.URL, "key", WithTLSConfig(&tls.Config{RootCAs: pool}))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	if err := client.do(context.Background(), http.MethodGet, "/", nil, nil); err != nil {
+		t.Fatalf("do error: %v", err)
+	}
+}
+
+func TestNewClient_TLSConfigNeedsHTTPTransport(t *testing.T) {
+	rt := roundTripFunc(func(r *http.Request) (*http.Response, error) { return nil, nil })
+	if _, err := NewClient("https://dokploy.example.com", "key", WithTransport(rt), WithTLSConfig(&tls.Config{})); err == nil {
+		t.Fatalf("expected error, got nil")
+	}
+}
+
+func TestNewClient_WithLoggerOmitsAPIKey(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	var buf bytes.Buffer
+	client, err := NewClient(ts.URL, "super-secret", WithLogger(log.New(&buf, "", 0)))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	if err := client.do(context.Background(), http.MethodGet, "/api/project.all", nil, nil); err != nil {
+		t.Fatalf("do error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "GET /api/project.all: 200 OK") {
+		t.Errorf("log = %q, want it to mention the request", buf.String())
+	}
+	if strings.Contains(buf.String(), "super-secret") {
+		t.Errorf("log contains the API key: %q", buf.String())
+	}
+}
@@ -2,11 +2,16 @@ package dokploy
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
+	"path"
 )
 
 // Project create: POST /api/project.create
+// Project get: GET /api/project.one?projectId=...
 // Project remove: POST /api/project.remove
 
 // projectCreateResponse reflects the real Dokploy response, which returns
@@ -64,13 +69,80 @@ type Project struct {
 	Environments   []ProjectEnvironment `json:"environments"`
 }
 
-// ProjectEnvironment represents an environment embedded in a project
-// response from project.all.
+// ProjectEnvironment represents an environment, as embedded in a project
+// response from project.all or returned by environment.one.
+//
+// Dokploy returns each database engine's services in its own array
+// (postgres, mysql, ...); they are collected into Databases.
 type ProjectEnvironment struct {
-	EnvironmentID string `json:"environmentId"`
+	EnvironmentID string               `json:"environmentId"`
+	Name          string               `json:"name"`
+	Description   string               `json:"description"`
+	CreatedAt     string               `json:"createdAt"`
+	ProjectID     string               `json:"projectId"`
+	Compose       []ProjectCompose     `json:"compose"`
+	Applications  []ProjectApplication `json:"applications"`
+	Databases     []ProjectDatabase    `json:"databases"`
+}
+
+// UnmarshalJSON decodes an environment and collects its per-engine database
+// arrays into Databases.
+func (e *ProjectEnvironment) UnmarshalJSON(data []byte) error {
+	type plain ProjectEnvironment
+	if err := json.Unmarshal(data, (*plain)(e)); err != nil {
+		return err
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	for _, t := range DatabaseTypes {
+		if len(raw[string(t)]) == 0 {
+			continue
+		}
+		var items []map[string]any
+		if err := json.Unmarshal(raw[string(t)], &items); err != nil {
+			return fmt.Errorf("environment %s: %w", t, err)
+		}
+		for _, item := range items {
+			db := ProjectDatabase{Type: t}
+			db.ID, _ = item[t.idField()].(string)
+			db.Name, _ = item["name"].(string)
+			db.AppName, _ = item["appName"].(string)
+			db.ApplicationStatus, _ = item["applicationStatus"].(string)
+			e.Databases = append(e.Databases, db)
+		}
+	}
+	return nil
+}
+
+// ProjectCompose is the compose app summary embedded in an environment
+// returned by project.all.
+type ProjectCompose struct {
+	ComposeID     string `json:"composeId"`
 	Name          string `json:"name"`
-	Description   string `json:"description"`
-	CreatedAt     string `json:"createdAt"`
+	AppName       string `json:"appName"`
+	ComposeStatus string `json:"composeStatus"`
+}
+
+// ProjectApplication is the application summary embedded in an environment
+// returned by project.all.
+type ProjectApplication struct {
+	ApplicationID     string `json:"applicationId"`
+	Name              string `json:"name"`
+	AppName           string `json:"appName"`
+	ApplicationStatus string `json:"applicationStatus"`
+}
+
+// ProjectDatabase is the database summary embedded in an environment
+// returned by project.all. ID is read from the engine's own ID field, such
+// as postgresId.
+type ProjectDatabase struct {
+	ID                string       `json:"id"`
+	Type              DatabaseType `json:"type"`
+	Name              string       `json:"name"`
+	AppName           string       `json:"appName"`
+	ApplicationStatus string       `json:"applicationStatus"`
 }
 
 // ListProjects calls GET /api/project.all and returns all projects.
@@ -82,9 +154,50 @@ func ListProjects(ctx context.Context, client *Client) ([]Project, error) {
 	return out, nil
 }
 
+// FilterProjects returns the projects whose name matches the glob pattern,
+// using path.Match syntax (*, ? and [...]). An empty pattern matches all.
+func FilterProjects(projects []Project, pattern string) ([]Project, error) {
+	if pattern == "" {
+		return projects, nil
+	}
+	out := []Project{}
+	for _, p := range projects {
+		ok, err := path.Match(pattern, p.Name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid name pattern %q: %w", pattern, err)
+		}
+		if ok {
+			out = append(out, p)
+		}
+	}
+	return out, nil
+}
+
+// GetProjectByID calls GET /api/project.one and returns the project with its
+// environments and their services.
+func GetProjectByID(ctx context.Context, client *Client, id string) (*Project, error) {
+	if id == "" {
+		return nil, errors.New("project id is required")
+	}
+	q := url.Values{}
+	q.Set("projectId", id)
+	var out Project
+	if err := client.do(ctx, http.MethodGet, "/api/project.one?"+q.Encode(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Errors returned by GetProject.
+var (
+	ErrProjectNotFound     = errors.New("project not found")
+	ErrEnvironmentNotFound = errors.New("environment not found for project")
+)
+
 // GetProject finds a project by name and one of its environments by envName,
 // using the data returned from ListProjects. It returns the matching
-// projectId and environmentId.
+// projectId and environmentId. If the project exists but the environment
+// does not, it returns the projectId with ErrEnvironmentNotFound.
 func GetProject(ctx context.Context, client *Client, name, envName string) (string, string, error) {
 	if name == "" {
 		return "", "", errors.New("project name is required")
@@ -107,8 +220,8 @@ func GetProject(ctx context.Context, client *Client, name, envName string) (stri
 				return p.ProjectID, e.EnvironmentID, nil
 			}
 		}
-		return "", "", errors.New("environment not found for project")
+		return p.ProjectID, "", ErrEnvironmentNotFound
 	}
 
-	return "", "", errors.New("project not found")
+	return "", "", ErrProjectNotFound
 }
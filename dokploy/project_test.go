@@ -3,6 +3,7 @@ package dokploy
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -202,8 +203,8 @@ func TestGetProject_ProjectNotFound(t *testing.T) {
 	ctx := context.Background()
 
 	_, _, err = GetProject(ctx, client, "missing", "production")
-	if err == nil {
-		t.Fatalf("expected error, got nil")
+	if !errors.Is(err, ErrProjectNotFound) {
+		t.Fatalf("err = %v, want ErrProjectNotFound", err)
 	}
 }
 
@@ -233,8 +234,104 @@ func TestGetProject_EnvironmentNotFound(t *testing.T) {
 	}
 	ctx := context.Background()
 
-	_, _, err = GetProject(ctx, client, "project-one", "staging")
-	if err == nil {
-		t.Fatalf("expected error, got nil")
+	projectID, _, err := GetProject(ctx, client, "project-one", "staging")
+	if !errors.Is(err, ErrEnvironmentNotFound) {
+		t.Fatalf("err = %v, want ErrEnvironmentNotFound", err)
+	}
+	if projectID != "proj-1" {
+		t.Errorf("projectID = %q, want %q", projectID, "proj-1")
+	}
+}
+
+func TestListProjects_CollectsServices(t *testing.T) {
+	t.Helper()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[{
+			"projectId": "proj-1",
+			"name": "shop",
+			"environments": [{
+				"environmentId": "env-1",
+				"name": "production",
+				"compose": [{"composeId": "cmp-1", "name": "web", "composeStatus": "done"}],
+				"applications": [{"applicationId": "app-1", "name": "api", "applicationStatus": "running"}],
+				"postgres": [{"postgresId": "pg-1", "name": "db", "applicationStatus": "idle"}],
+				"redis": [{"redisId": "rd-1", "name": "cache", "applicationStatus": "done"}],
+				"mysql": null
+			}]
+		}]`))
+	}))
+	defer ts.Close()
+
+	client, err := NewClient(ts.URL, "key")
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+
+	projects, err := ListProjects(context.Background(), client)
+	if err != nil {
+		t.Fatalf("ListProjects error: %v", err)
+	}
+	env := projects[0].Environments[0]
+	if len(env.Compose) != 1 || len(env.Applications) != 1 || env.Applications[0].ApplicationID != "app-1" {
+		t.Errorf("env = %+v, want one compose app and application app-1", env)
+	}
+	want := []ProjectDatabase{
+		{ID: "pg-1", Type: Postgres, Name: "db", ApplicationStatus: "idle"},
+		{ID: "rd-1", Type: Redis, Name: "cache", ApplicationStatus: "done"},
+	}
+	if len(env.Databases) != len(want) {
+		t.Fatalf("Databases = %+v, want %+v", env.Databases, want)
+	}
+	for i := range want {
+		if env.Databases[i] != want[i] {
+			t.Errorf("Databases[%d] = %+v, want %+v", i, env.Databases[i], want[i])
+		}
+	}
+}
+
+func TestGetProjectByID_CallsProjectOne(t *testing.T) {
+	t.Helper()
+
+	var gotQuery string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/project.one" || r.Method != http.MethodGet {
+			t.Errorf("request = %s %s, want GET /api/project.one", r.Method, r.URL.Path)
+		}
+		gotQuery = r.URL.Query().Get("projectId")
+		_ = json.NewEncoder(w).Encode(Project{ProjectID: "proj-1", Name: "shop"})
+	}))
+	defer ts.Close()
+
+	client, err := NewClient(ts.URL, "key")
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+
+	project, err := GetProjectByID(context.Background(), client, "proj-1")
+	if err != nil {
+		t.Fatalf("GetProjectByID error: %v", err)
+	}
+	if gotQuery != "proj-1" {
+		t.Errorf("projectId = %q, want %q", gotQuery, "proj-1")
+	}
+	if project.Name != "shop" {
+		t.Errorf("Name = %q, want %q", project.Name, "shop")
+	}
+}
+
+func TestFilterProjects_MatchesGlob(t *testing.T) {
+	projects := []Project{{Name: "shop-prod"}, {Name: "shop-dev"}, {Name: "blog"}}
+
+	got, err := FilterProjects(projects, "shop-*")
+	if err != nil {
+		t.Fatalf("FilterProjects error: %v", err)
+	}
+	if len(got) != 2 || got[0].Name != "shop-prod" || got[1].Name != "shop-dev" {
+		t.Errorf("FilterProjects = %+v, want shop-prod and shop-dev", got)
+	}
+
+	if _, err := FilterProjects(projects, "shop-["); err == nil {
+		t.Errorf("FilterProjects with a bad pattern returned nil error")
 	}
 }
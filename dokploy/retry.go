@@ -0,0 +1,125 @@
+package dokploy
+
+import (
+	"context"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy controls how Client retries failed requests. Requests are
+// retried on network errors and on RetryableStatuses, waiting an
+// exponentially growing, jittered delay between attempts, or the server's
+// Retry-After when it sends one.
+//
+// Only GET and HEAD requests are retried unless RetryPOST is set: most
+// Dokploy POST endpoints create or deploy something, and replaying one whose
+// response was lost can do it twice.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first.
+	// Values below 2 disable retries.
+	MaxAttempts int
+	// BaseDelay is the delay before the first retry; it doubles on every
+	// subsequent retry.
+	BaseDelay time.Duration
+	// MaxDelay caps the delay between attempts, including Retry-After.
+	MaxDelay time.Duration
+	// RetryableStatuses lists the HTTP status codes worth retrying.
+	RetryableStatuses []int
+	// RetryPOST also retries non-idempotent POST requests.
+	RetryPOST bool
+}
+
+// DefaultRetryPolicy returns the policy used by NewClient: up to three
+// attempts for GET requests on gateway errors and rate limiting.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   500 * time.Millisecond,
+		MaxDelay:    10 * time.Second,
+		RetryableStatuses: []int{
+			http.StatusTooManyRequests,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+	}
+}
+
+// WithRetryPolicy replaces the client's retry policy. Pass a policy with
+// MaxAttempts of 1 to disable retries.
+func WithRetryPolicy(p RetryPolicy) ClientOption {
+	return func(c *Client) {
+		c.retry = p
+	}
+}
+
+// attempts returns how many times a request with method may be sent.
+func (p RetryPolicy) attempts(method string) int {
+	if p.MaxAttempts < 2 {
+		return 1
+	}
+	switch method {
+	case http.MethodGet, http.MethodHead:
+		return p.MaxAttempts
+	case http.MethodPost:
+		if p.RetryPOST {
+			return p.MaxAttempts
+		}
+	}
+	return 1
+}
+
+func (p RetryPolicy) retryable(status int) bool {
+	return slices.Contains(p.RetryableStatuses, status)
+}
+
+// delay returns how long to wait before retry number n (starting at 1).
+// A Retry-After header given in seconds or as an HTTP date takes precedence
+// over the computed backoff.
+func (p RetryPolicy) delay(n int, retryAfter string) time.Duration {
+	if retryAfter != "" {
+		if secs, err := strconv.Atoi(retryAfter); err == nil && secs >= 0 {
+			return p.capDelay(time.Duration(secs) * time.Second)
+		}
+		if t, err := http.ParseTime(retryAfter); err == nil {
+			return p.capDelay(max(time.Until(t), 0))
+		}
+	}
+	if p.BaseDelay <= 0 {
+		return 0
+	}
+	// Full jitter: a random delay up to the exponential backoff, so that
+	// clients failing together don't retry together.
+	// Without MaxDelay, stop doubling before the backoff overflows.
+	backoff := p.BaseDelay
+	for i := 1; i < n && (p.MaxDelay <= 0 || backoff < p.MaxDelay) && backoff <= math.MaxInt64/2; i++ {
+		backoff *= 2
+	}
+	return rand.N(p.capDelay(backoff)) + 1
+}
+
+func (p RetryPolicy) capDelay(d time.Duration) time.Duration {
+	if p.MaxDelay > 0 && d > p.MaxDelay {
+		return p.MaxDelay
+	}
+	return d
+}
+
+// sleepCtx waits for d or until ctx is done, whichever comes first.
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
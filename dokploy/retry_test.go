@@ -0,0 +1,152 @@
+package dokploy
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func testRetryPolicy() RetryPolicy {
+	p := DefaultRetryPolicy()
+	p.BaseDelay = time.Millisecond
+	p.MaxDelay = 5 * time.Millisecond
+	return p
+}
+
+func TestClientDo_RetriesGETOnRetryableStatus(t *testing.T) {
+	t.Helper()
+
+	calls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	}))
+	defer ts.Close()
+
+	client, err := NewClient(ts.URL, "key", WithRetryPolicy(testRetryPolicy()))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+
+	var out struct {
+		OK bool `json:"ok"`
+	}
+	if err := client.do(context.Background(), http.MethodGet, "/api/project.all", nil, &out); err != nil {
+		t.Fatalf("do error: %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("calls = %d, want 3", calls)
+	}
+	if !out.OK {
+		t.Errorf("out.OK = false, want true")
+	}
+}
+
+func TestClientDo_GivesUpAfterMaxAttempts(t *testing.T) {
+	calls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer ts.Close()
+
+	client, err := NewClient(ts.URL, "key", WithRetryPolicy(testRetryPolicy()))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+
+	err = client.do(context.Background(), http.MethodGet, "/api/project.all", nil, nil)
+	if err == nil {
+		t.Fatalf("expected error, got nil")
+	}
+	if calls != 3 {
+		t.Errorf("calls = %d, want 3", calls)
+	}
+}
+
+func TestClientDo_RetriesPOSTOnlyWhenEnabled(t *testing.T) {
+	for _, retryPOST := range []bool{false, true} {
+		calls := 0
+		var bodies []string
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			b, _ := io.ReadAll(r.Body)
+			bodies = append(bodies, string(b))
+			if calls == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+
+		policy := testRetryPolicy()
+		policy.RetryPOST = retryPOST
+		client, err := NewClient(ts.URL, "key", WithRetryPolicy(policy))
+		if err != nil {
+			t.Fatalf("NewClient error: %v", err)
+		}
+
+		err = client.do(context.Background(), http.MethodPost, "/api/compose.deploy", map[string]any{"composeId": "cmp-1"}, nil)
+		ts.Close()
+
+		if !retryPOST {
+			if err == nil || calls != 1 {
+				t.Errorf("RetryPOST=false: calls = %d, err = %v; want 1 call and an error", calls, err)
+			}
+			continue
+		}
+		if err != nil || calls != 2 {
+			t.Errorf("RetryPOST=true: calls = %d, err = %v; want 2 calls and no error", calls, err)
+		}
+		if len(bodies) == 2 && bodies[0] != bodies[1] {
+			t.Errorf("retried body = %q, want %q", bodies[1], bodies[0])
+		}
+	}
+}
+
+func TestRetryPolicy_Delay(t *testing.T) {
+	p := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+
+	for n := 1; n <= 6; n++ {
+		d := p.delay(n, "")
+		if d <= 0 || d > time.Second {
+			t.Errorf("delay(%d) = %v, want within (0, 1s]", n, d)
+		}
+	}
+	if d := p.delay(1, "3"); d != time.Second {
+		t.Errorf("delay with Retry-After 3 = %v, want capped at 1s", d)
+	}
+	if d := p.delay(1, "0"); d != 0 {
+		t.Errorf("delay with Retry-After 0 = %v, want 0", d)
+	}
+}
+
+func TestRetryPolicy_DelayWithoutMaxDoesNotOverflow(t *testing.T) {
+	p := RetryPolicy{BaseDelay: time.Second}
+
+	for _, n := range []int{36, 64, 1000} {
+		if d := p.delay(n, ""); d <= 0 {
+			t.Errorf("delay(%d) = %v, want > 0", n, d)
+		}
+	}
+}
+
+func TestRetryPolicy_Attempts(t *testing.T) {
+	p := DefaultRetryPolicy()
+	if got := p.attempts(http.MethodGet); got != 3 {
+		t.Errorf("attempts(GET) = %d, want 3", got)
+	}
+	if got := p.attempts(http.MethodPost); got != 1 {
+		t.Errorf("attempts(POST) = %d, want 1", got)
+	}
+	if got := (RetryPolicy{}).attempts(http.MethodGet); got != 1 {
+		t.Errorf("zero policy attempts(GET) = %d, want 1", got)
+	}
+}
@@ -0,0 +1,105 @@
+package dokploy
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// Server version: GET /api/settings.getDokployVersion
+// Current user: GET /api/user.get
+
+// GetServerVersion calls GET /api/settings.getDokployVersion and returns the
+// Dokploy version, such as "v0.24.1".
+func GetServerVersion(ctx context.Context, client *Client) (string, error) {
+	var version string
+	if err := client.do(ctx, http.MethodGet, "/api/settings.getDokployVersion", nil, &version); err != nil {
+		return "", err
+	}
+	return version, nil
+}
+
+// User is the account an API key belongs to, as returned by user.get.
+// Permissions holds the member's can* flags, such as canCreateProjects,
+// keyed by name; owners and admins may have none listed.
+type User struct {
+	ID             string          `json:"id"`
+	Name           string          `json:"name"`
+	Email          string          `json:"email"`
+	Role           string          `json:"role"`
+	OrganizationID string          `json:"organizationId"`
+	Permissions    map[string]bool `json:"permissions,omitempty"`
+}
+
+// GrantedPermissions returns the names of the permissions set to true, in
+// sorted order.
+func (u *User) GrantedPermissions() []string {
+	var out []string
+	for name, ok := range u.Permissions {
+		if ok {
+			out = append(out, name)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// GetCurrentUser calls GET /api/user.get. Recent Dokploy versions return the
+// organization membership with the user nested under "user"; older ones
+// return the user itself. Both are read into a User.
+func GetCurrentUser(ctx context.Context, client *Client) (*User, error) {
+	var raw map[string]any
+	if err := client.do(ctx, http.MethodGet, "/api/user.get", nil, &raw); err != nil {
+		return nil, err
+	}
+
+	str := func(m map[string]any, keys ...string) string {
+		for _, k := range keys {
+			if s, ok := m[k].(string); ok && s != "" {
+				return s
+			}
+		}
+		return ""
+	}
+	u := &User{
+		ID:             str(raw, "userId", "id"),
+		Name:           str(raw, "name"),
+		Email:          str(raw, "email"),
+		Role:           str(raw, "role"),
+		OrganizationID: str(raw, "organizationId"),
+	}
+	if nested, ok := raw["user"].(map[string]any); ok {
+		u.ID = str(nested, "id", "userId")
+		if name := str(nested, "name"); name != "" {
+			u.Name = name
+		}
+		if email := str(nested, "email"); email != "" {
+			u.Email = email
+		}
+	}
+	for k, v := range raw {
+		if b, ok := v.(bool); ok && strings.HasPrefix(k, "can") {
+			if u.Permissions == nil {
+				u.Permissions = map[string]bool{}
+			}
+			u.Permissions[k] = b
+		}
+	}
+	return u, nil
+}
+
+// String returns a one-line description such as "ops@example.com (admin)".
+func (u *User) String() string {
+	who := u.Email
+	if who == "" {
+		who = u.Name
+	}
+	if who == "" {
+		who = u.ID
+	}
+	if u.Role != "" {
+		who += " (" + u.Role + ")"
+	}
+	return who
+}
@@ -0,0 +1,108 @@
+package dokploy
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestGetServerVersion_CallsGetDokployVersion(t *testing.T) {
+	t.Helper()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/settings.getDokployVersion" || r.Method != http.MethodGet {
+			t.Errorf("request = %s %s, want GET /api/settings.getDokployVersion", r.Method, r.URL.Path)
+		}
+		_ = json.NewEncoder(w).Encode("v0.24.1")
+	}))
+	defer ts.Close()
+
+	client, err := NewClient(ts.URL, "key")
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+
+	v, err := GetServerVersion(context.Background(), client)
+	if err != nil {
+		t.Fatalf("GetServerVersion error: %v", err)
+	}
+	if v != "v0.24.1" {
+		t.Errorf("version = %q, want %q", v, "v0.24.1")
+	}
+}
+
+func TestGetCurrentUser_ReadsMembership(t *testing.T) {
+	t.Helper()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/user.get" || r.Method != http.MethodGet {
+			t.Errorf("request = %s %s, want GET /api/user.get", r.Method, r.URL.Path)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"id":                "member-1",
+			"userId":            "user-1",
+			"organizationId":    "org-1",
+			"role":              "member",
+			"canCreateProjects": true,
+			"canDeleteProjects": false,
+			"canAccessToDocker": true,
+			"user": map[string]any{
+				"id":    "user-1",
+				"name":  "Ops",
+				"email": "ops@example.com",
+			},
+		})
+	}))
+	defer ts.Close()
+
+	client, err := NewClient(ts.URL, "key")
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+
+	u, err := GetCurrentUser(context.Background(), client)
+	if err != nil {
+		t.Fatalf("GetCurrentUser error: %v", err)
+	}
+	if u.ID != "user-1" || u.Email != "ops@example.com" || u.Role != "member" || u.OrganizationID != "org-1" {
+		t.Errorf("user = %+v, want user-1, ops@example.com, member, org-1", u)
+	}
+	if got, want := u.GrantedPermissions(), []string{"canAccessToDocker", "canCreateProjects"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("GrantedPermissions() = %v, want %v", got, want)
+	}
+	if got := u.String(); got != "ops@example.com (member)" {
+		t.Errorf("String() = %q, want %q", got, "ops@example.com (member)")
+	}
+}
+
+func TestGetCurrentUser_ReadsFlatUser(t *testing.T) {
+	t.Helper()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"id":    "user-1",
+			"email": "admin@example.com",
+			"role":  "owner",
+		})
+	}))
+	defer ts.Close()
+
+	client, err := NewClient(ts.URL, "key")
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+
+	u, err := GetCurrentUser(context.Background(), client)
+	if err != nil {
+		t.Fatalf("GetCurrentUser error: %v", err)
+	}
+	if u.ID != "user-1" || u.String() != "admin@example.com (owner)" {
+		t.Errorf("user = %+v, want user-1 admin@example.com (owner)", u)
+	}
+	if len(u.GrantedPermissions()) != 0 {
+		t.Errorf("GrantedPermissions() = %v, want none", u.GrantedPermissions())
+	}
+}
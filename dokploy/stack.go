@@ -0,0 +1,526 @@
+package dokploy
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Stack is a declarative description of a project, its environments and the
+// compose apps and domains that live in them. It is loaded from a YAML
+// manifest and converged onto a Dokploy server with ApplyStack.
+type Stack struct {
+	Project      string             `yaml:"project"`
+	Description  string             `yaml:"description"`
+	Environments []StackEnvironment `yaml:"environments"`
+}
+
+// StackEnvironment describes one environment of a Stack.
+type StackEnvironment struct {
+	Name    string         `yaml:"name"`
+	Compose []StackCompose `yaml:"compose"`
+}
+
+// StackCompose describes a compose app. File is the path to the docker
+// compose file, relative to the manifest; LoadStack reads it into Content.
+// Env is sent in the order the manifest lists it.
+type StackCompose struct {
+	Name    string            `yaml:"name"`
+	File    string            `yaml:"file"`
+	Env     map[string]string `yaml:"env"`
+	Domains []StackDomain     `yaml:"domains"`
+
+	Content string `yaml:"-"`
+
+	// envOrder is the order ParseStack read the keys of Env in.
+	envOrder []string
+}
+
+// envDocument returns Env in the order it was written in the manifest.
+// Keys ParseStack did not read, such as ones set in code, follow sorted.
+func (c *StackCompose) envDocument() *EnvDocument {
+	doc := &EnvDocument{}
+	seen := map[string]bool{}
+	for _, k := range c.envOrder {
+		if v, ok := c.Env[k]; ok && !seen[k] {
+			doc.Append(k, v)
+			seen[k] = true
+		}
+	}
+	rest := map[string]string{}
+	for k, v := range c.Env {
+		if !seen[k] {
+			rest[k] = v
+		}
+	}
+	doc.Concat(EnvFromMap(rest))
+	return doc
+}
+
+// StackDomain describes a domain routed to a service of a compose app.
+type StackDomain struct {
+	Host            string `yaml:"host"`
+	Path            string `yaml:"path"`
+	Port            int    `yaml:"port"`
+	ServiceName     string `yaml:"serviceName"`
+	CertificateType string `yaml:"certificateType"`
+	HTTPS           bool   `yaml:"https"`
+}
+
+// LoadStack reads a stack manifest, applies defaults, validates it and reads
+// every referenced compose file relative to the manifest's directory.
+func LoadStack(path string) (*Stack, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	stack, err := ParseStack(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	dir := filepath.Dir(path)
+	for i := range stack.Environments {
+		env := &stack.Environments[i]
+		for j := range env.Compose {
+			cmp := &env.Compose[j]
+			file := cmp.File
+			if !filepath.IsAbs(file) {
+				file = filepath.Join(dir, file)
+			}
+			content, err := os.ReadFile(file)
+			if err != nil {
+				return nil, fmt.Errorf("compose %q: %w", cmp.Name, err)
+			}
+			cmp.Content = string(content)
+		}
+	}
+	return stack, nil
+}
+
+// ParseStack decodes a stack manifest, applies defaults and validates it. It
+// does not read compose files; see LoadStack.
+func ParseStack(data []byte) (*Stack, error) {
+	var stack Stack
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&stack); err != nil {
+		return nil, err
+	}
+	// A map loses the order env is written in; read it from the nodes.
+	var order struct {
+		Environments []struct {
+			Compose []struct {
+				Env yaml.Node `yaml:"env"`
+			} `yaml:"compose"`
+		} `yaml:"environments"`
+	}
+	if err := yaml.Unmarshal(data, &order); err != nil {
+		return nil, err
+	}
+	for i, env := range order.Environments {
+		for j, cmp := range env.Compose {
+			for k := 0; k+1 < len(cmp.Env.Content); k += 2 {
+				stack.Environments[i].Compose[j].envOrder = append(stack.Environments[i].Compose[j].envOrder, cmp.Env.Content[k].Value)
+			}
+		}
+	}
+
+	if stack.Project == "" {
+		return nil, errors.New("project is required")
+	}
+	if len(stack.Environments) == 0 {
+		return nil, errors.New("at least one environment is required")
+	}
+	seenEnv := map[string]bool{}
+	for i := range stack.Environments {
+		env := &stack.Environments[i]
+		if env.Name == "" {
+			return nil, fmt.Errorf("environments[%d]: name is required", i)
+		}
+		if seenEnv[env.Name] {
+			return nil, fmt.Errorf("environment %q is declared twice", env.Name)
+		}
+		seenEnv[env.Name] = true
+
+		seenCompose := map[string]bool{}
+		for j := range env.Compose {
+			cmp := &env.Compose[j]
+			if cmp.Name == "" {
+				return nil, fmt.Errorf("environment %q: compose[%d]: name is required", env.Name, j)
+			}
+			if seenCompose[cmp.Name] {
+				return nil, fmt.Errorf("environment %q: compose %q is declared twice", env.Name, cmp.Name)
+			}
+			seenCompose[cmp.Name] = true
+			if cmp.File == "" {
+				return nil, fmt.Errorf("compose %q: file is required", cmp.Name)
+			}
+
+			for k := range cmp.Domains {
+				d := &cmp.Domains[k]
+				if d.Path == "" {
+					d.Path = "/"
+				}
+				if d.CertificateType == "" {
+					d.CertificateType = "none"
+				}
+				if d.Host == "" {
+					return nil, fmt.Errorf("compose %q: domains[%d]: host is required", cmp.Name, k)
+				}
+				if d.Port == 0 {
+					return nil, fmt.Errorf("compose %q: domain %q: port is required", cmp.Name, d.Host)
+				}
+				if d.ServiceName == "" {
+					return nil, fmt.Errorf("compose %q: domain %q: serviceName is required", cmp.Name, d.Host)
+				}
+				switch d.CertificateType {
+				case "none", "letsencrypt":
+				default:
+					return nil, fmt.Errorf("compose %q: domain %q: invalid certificateType %q, must be one of: none, letsencrypt", cmp.Name, d.Host, d.CertificateType)
+				}
+			}
+		}
+	}
+	return &stack, nil
+}
+
+// ApplyAction describes what ApplyStack did, or what PlanStack would do, to
+// a resource.
+type ApplyAction string
+
+const (
+	ActionCreated   ApplyAction = "created"
+	ActionUpdated   ApplyAction = "updated"
+	ActionUnchanged ApplyAction = "unchanged"
+)
+
+// ApplyChange records the outcome of converging a single resource. Diffs
+// lists the fields that differ between the server and the stack for
+// resources that are updated.
+type ApplyChange struct {
+	Resource string      `json:"resource"`
+	Name     string      `json:"name"`
+	ID       string      `json:"id"`
+	Action   ApplyAction `json:"action"`
+	Diffs    []FieldDiff `json:"diffs,omitempty"`
+}
+
+// FieldDiff is a single field whose current server value differs from the
+// value desired by the stack. An empty Current means the field is new and an
+// empty Desired means it would be removed.
+type FieldDiff struct {
+	Field   string `json:"field"`
+	Current string `json:"current"`
+	Desired string `json:"desired"`
+}
+
+// ApplyStack converges the server to the state described by stack. Resources
+// are matched by name (domains by host and path), created when missing and
+// updated only when they differ, so running it twice is a no-op. Resources
+// on the server that the stack does not mention are left untouched.
+func ApplyStack(ctx context.Context, client *Client, stack *Stack) ([]ApplyChange, error) {
+	return reconcileStack(ctx, client, stack, false)
+}
+
+// PlanStack reports the changes ApplyStack would make without writing
+// anything to the server. Resources that would be created have no ID.
+func PlanStack(ctx context.Context, client *Client, stack *Stack) ([]ApplyChange, error) {
+	return reconcileStack(ctx, client, stack, true)
+}
+
+// HasDrift reports whether any change would create or update a resource.
+func HasDrift(changes []ApplyChange) bool {
+	for _, ch := range changes {
+		if ch.Action != ActionUnchanged {
+			return true
+		}
+	}
+	return false
+}
+
+func reconcileStack(ctx context.Context, client *Client, stack *Stack, dryRun bool) ([]ApplyChange, error) {
+	var changes []ApplyChange
+
+	projects, err := ListProjects(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	var project *Project
+	for i := range projects {
+		if projects[i].Name == stack.Project {
+			project = &projects[i]
+			break
+		}
+	}
+
+	// A new project comes with one environment; name it after the first
+	// environment of the stack.
+	if project == nil {
+		first := stack.Environments[0].Name
+		project = &Project{
+			Name:         stack.Project,
+			Environments: []ProjectEnvironment{{Name: first}},
+		}
+		if !dryRun {
+			projectID, envID, err := CreateProject(ctx, client, stack.Project, stack.Description, first)
+			if err != nil {
+				return nil, err
+			}
+			project.ProjectID = projectID
+			project.Environments[0].EnvironmentID = envID
+		}
+		changes = append(changes, ApplyChange{Resource: "project", Name: stack.Project, ID: project.ProjectID, Action: ActionCreated})
+	} else {
+		changes = append(changes, ApplyChange{Resource: "project", Name: stack.Project, ID: project.ProjectID, Action: ActionUnchanged})
+	}
+
+	for _, env := range stack.Environments {
+		var current *ProjectEnvironment
+		for i := range project.Environments {
+			if project.Environments[i].Name == env.Name {
+				current = &project.Environments[i]
+				break
+			}
+		}
+		if current == nil {
+			current = &ProjectEnvironment{Name: env.Name, ProjectID: project.ProjectID}
+			if !dryRun {
+				envID, err := CreateEnvironment(ctx, client, project.ProjectID, env.Name, "")
+				if err != nil {
+					return changes, fmt.Errorf("create environment %q: %w", env.Name, err)
+				}
+				current.EnvironmentID = envID
+			}
+			changes = append(changes, ApplyChange{Resource: "environment", Name: env.Name, ID: current.EnvironmentID, Action: ActionCreated})
+		} else {
+			changes = append(changes, ApplyChange{Resource: "environment", Name: env.Name, ID: current.EnvironmentID, Action: ActionUnchanged})
+		}
+
+		for _, cmp := range env.Compose {
+			cmpChanges, err := reconcileStackCompose(ctx, client, current, cmp, dryRun)
+			changes = append(changes, cmpChanges...)
+			if err != nil {
+				return changes, err
+			}
+		}
+	}
+	return changes, nil
+}
+
+func reconcileStackCompose(ctx context.Context, client *Client, env *ProjectEnvironment, cmp StackCompose, dryRun bool) ([]ApplyChange, error) {
+	var changes []ApplyChange
+
+	var composeID string
+	for _, c := range env.Compose {
+		if c.Name != cmp.Name {
+			continue
+		}
+		if composeID != "" {
+			return nil, fmt.Errorf("environment %q has more than one compose named %q", env.Name, cmp.Name)
+		}
+		composeID = c.ComposeID
+	}
+
+	change := ApplyChange{Resource: "compose", Name: cmp.Name, ID: composeID}
+	if composeID == "" {
+		change.Action = ActionCreated
+		if !dryRun {
+			id, err := CreateOrUpdateComposeWithEnv(ctx, client, "", cmp.Name, env.EnvironmentID, cmp.Content, cmp.envDocument())
+			if err != nil {
+				return nil, err
+			}
+			composeID = id
+			change.ID = id
+		}
+	} else {
+		current, err := GetComposeByID(ctx, client, composeID)
+		if err != nil {
+			return nil, err
+		}
+		if current.ComposeFile != cmp.Content {
+			change.Diffs = append(change.Diffs, FieldDiff{Field: "composeFile", Current: current.ComposeFile, Desired: cmp.Content})
+		}
+		// An empty env block leaves the server's env alone, matching
+		// CreateOrUpdateComposeWithEnv which never sends an empty env.
+		if len(cmp.Env) > 0 {
+			var currentEnv string
+			if current.Env != nil {
+				currentEnv = *current.Env
+			}
+			change.Diffs = append(change.Diffs, diffEnv(currentEnv, cmp.Env)...)
+		}
+		if len(change.Diffs) == 0 {
+			change.Action = ActionUnchanged
+		} else {
+			change.Action = ActionUpdated
+			if !dryRun {
+				if _, err := CreateOrUpdateComposeWithEnv(ctx, client, composeID, cmp.Name, env.EnvironmentID, cmp.Content, cmp.envDocument()); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	changes = append(changes, change)
+
+	if len(cmp.Domains) == 0 {
+		return changes, nil
+	}
+	var existing []Domain
+	if composeID != "" {
+		var err error
+		existing, err = listComposeDomains(ctx, client, composeID)
+		if err != nil {
+			return changes, err
+		}
+	}
+	for _, d := range cmp.Domains {
+		change := ApplyChange{Resource: "domain", Name: d.Host + d.Path}
+		payload := domainPayload(d.Host, d.Path, d.Port, d.ServiceName, composeID, d.CertificateType, d.HTTPS)
+
+		var match *Domain
+		for i := range existing {
+			if existing[i].Host == d.Host && existing[i].Path == d.Path {
+				match = &existing[i]
+				break
+			}
+		}
+		if match == nil {
+			change.Action = ActionCreated
+			if !dryRun {
+				id, err := saveDomain(ctx, client, "", payload)
+				if err != nil {
+					return changes, err
+				}
+				change.ID = id
+			}
+			changes = append(changes, change)
+			continue
+		}
+
+		change.ID = match.DomainID
+		change.Diffs = diffDomain(*match, d)
+		if len(change.Diffs) == 0 {
+			change.Action = ActionUnchanged
+		} else {
+			change.Action = ActionUpdated
+			if !dryRun {
+				if _, err := saveDomain(ctx, client, match.DomainID, payload); err != nil {
+					return changes, err
+				}
+			}
+		}
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
+
+// diffEnv compares Dokploy's env string against the desired variables,
+// key by key, in sorted key order.
+func diffEnv(current string, desired map[string]string) []FieldDiff {
+	have, err := ParseEnv(current, nil)
+	if err != nil {
+		// Compare line by line what cannot be parsed, such as env edited
+		// by hand in Dokploy.
+		have = map[string]string{}
+		for _, line := range strings.Split(current, "\n") {
+			k, v, ok := strings.Cut(line, "=")
+			if !ok || strings.HasPrefix(strings.TrimSpace(k), "#") {
+				continue
+			}
+			have[k] = v
+		}
+	}
+
+	keys := make([]string, 0, len(have)+len(desired))
+	for k := range have {
+		keys = append(keys, k)
+	}
+	for k := range desired {
+		if _, ok := have[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	var diffs []FieldDiff
+	for _, k := range keys {
+		cur, inCurrent := have[k]
+		want, inDesired := desired[k]
+		if inCurrent && inDesired && cur == want {
+			continue
+		}
+		diffs = append(diffs, FieldDiff{Field: "env." + k, Current: cur, Desired: want})
+	}
+	return diffs
+}
+
+func diffDomain(current Domain, desired StackDomain) []FieldDiff {
+	var diffs []FieldDiff
+	add := func(field, cur, want string) {
+		if cur != want {
+			diffs = append(diffs, FieldDiff{Field: field, Current: cur, Desired: want})
+		}
+	}
+	add("port", strconv.Itoa(current.Port), strconv.Itoa(desired.Port))
+	add("serviceName", current.ServiceName, desired.ServiceName)
+	add("certificateType", current.CertificateType, desired.CertificateType)
+	add("https", strconv.FormatBool(current.HTTPS), strconv.FormatBool(desired.HTTPS))
+	return diffs
+}
+
+// LineDiff returns a line-by-line diff of two multi-line values. Lines only
+// in current are prefixed with "- ", lines only in desired with "+ " and
+// common lines with two spaces.
+func LineDiff(current, desired string) []string {
+	a := strings.Split(current, "\n")
+	b := strings.Split(desired, "\n")
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:]
+	// and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var out []string
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			out = append(out, "  "+a[i])
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			out = append(out, "- "+a[i])
+			i++
+		default:
+			out = append(out, "+ "+b[j])
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		out = append(out, "- "+a[i])
+	}
+	for ; j < len(b); j++ {
+		out = append(out, "+ "+b[j])
+	}
+	return out
+}
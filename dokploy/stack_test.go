@@ -0,0 +1,459 @@
+package dokploy
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseStack_AppliesDomainDefaults(t *testing.T) {
+	t.Helper()
+
+	stack, err := ParseStack([]byte(`
+project: shop
+environments:
+  - name: production
+    compose:
+      - name: web
+        file: docker-compose.yml
+        env:
+          APP_ENV: production
+        domains:
+          - host: shop.example.com
+            port: 80
+            serviceName: web
+`))
+	if err != nil {
+		t.Fatalf("ParseStack error: %v", err)
+	}
+	d := stack.Environments[0].Compose[0].Domains[0]
+	if d.Path != "/" {
+		t.Errorf("path = %q, want %q", d.Path, "/")
+	}
+	if d.CertificateType != "none" {
+		t.Errorf("certificateType = %q, want %q", d.CertificateType, "none")
+	}
+}
+
+func TestApplyStack_SendsEnvInManifestOrder(t *testing.T) {
+	t.Helper()
+
+	stack, err := ParseStack([]byte(`
+project: shop
+environments:
+  - name: production
+    compose:
+      - name: web
+        file: docker-compose.yml
+        env:
+          ZONE: eu
+          APP_ENV: production
+          MODE: fast
+`))
+	if err != nil {
+		t.Fatalf("ParseStack error: %v", err)
+	}
+	stack.Environments[0].Compose[0].Env["EXTRA"] = "1"
+
+	var gotEnv any
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/project.all", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode([]Project{{
+			ProjectID:    "proj-1",
+			Name:         "shop",
+			Environments: []ProjectEnvironment{{EnvironmentID: "env-1", Name: "production"}},
+		}})
+	})
+	mux.HandleFunc("/api/compose.create", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		gotEnv = body["env"]
+		_ = json.NewEncoder(w).Encode(map[string]any{"composeId": "cmp-1"})
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+	client, err := NewClient(ts.URL, "key")
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+
+	if _, err := ApplyStack(context.Background(), client, stack); err != nil {
+		t.Fatalf("ApplyStack error: %v", err)
+	}
+	// Keys added in code follow the manifest's, sorted.
+	if want := "ZONE=eu\nAPP_ENV=production\nMODE=fast\nEXTRA=1"; gotEnv != want {
+		t.Errorf("env = %q, want %q", gotEnv, want)
+	}
+}
+
+func TestParseStack_RejectsInvalidManifests(t *testing.T) {
+	cases := map[string]string{
+		"missing project":   "environments: [{name: production}]",
+		"no environments":   "project: shop",
+		"unknown field":     "project: shop\nenvironments: [{name: production}]\nfoo: bar",
+		"duplicate compose": "project: shop\nenvironments: [{name: production, compose: [{name: web, file: a.yml}, {name: web, file: b.yml}]}]",
+		"missing port":      "project: shop\nenvironments: [{name: production, compose: [{name: web, file: a.yml, domains: [{host: a.com, serviceName: web}]}]}]",
+		"bad certificate":   "project: shop\nenvironments: [{name: production, compose: [{name: web, file: a.yml, domains: [{host: a.com, port: 80, serviceName: web, certificateType: custom}]}]}]",
+	}
+	for name, manifest := range cases {
+		if _, err := ParseStack([]byte(manifest)); err == nil {
+			t.Errorf("%s: expected error, got nil", name)
+		}
+	}
+}
+
+func TestLoadStack_ReadsComposeFileRelativeToManifest(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "docker-compose.yml"), []byte("services: {}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	manifest := "project: shop\nenvironments:\n  - name: production\n    compose:\n      - name: web\n        file: docker-compose.yml\n"
+	if err := os.WriteFile(filepath.Join(dir, "stack.yaml"), []byte(manifest), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	stack, err := LoadStack(filepath.Join(dir, "stack.yaml"))
+	if err != nil {
+		t.Fatalf("LoadStack error: %v", err)
+	}
+	if got := stack.Environments[0].Compose[0].Content; got != "services: {}" {
+		t.Errorf("content = %q, want %q", got, "services: {}")
+	}
+}
+
+func TestApplyStack_CreatesMissingResources(t *testing.T) {
+	t.Helper()
+
+	var gotPaths []string
+	var gotComposeBody, gotDomainBody map[string]any
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/project.all", func(w http.ResponseWriter, r *http.Request) {
+		gotPaths = append(gotPaths, r.URL.Path)
+		_ = json.NewEncoder(w).Encode([]Project{})
+	})
+	mux.HandleFunc("/api/project.create", func(w http.ResponseWriter, r *http.Request) {
+		gotPaths = append(gotPaths, r.URL.Path)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"project":     map[string]any{"projectId": "proj-1"},
+			"environment": map[string]any{"environmentId": "env-1", "name": "production"},
+		})
+	})
+	mux.HandleFunc("/api/compose.create", func(w http.ResponseWriter, r *http.Request) {
+		gotPaths = append(gotPaths, r.URL.Path)
+		_ = json.NewDecoder(r.Body).Decode(&gotComposeBody)
+		_ = json.NewEncoder(w).Encode(map[string]any{"composeId": "cmp-1"})
+	})
+	mux.HandleFunc("/api/domain.byComposeId", func(w http.ResponseWriter, r *http.Request) {
+		gotPaths = append(gotPaths, r.URL.Path)
+		_ = json.NewEncoder(w).Encode([]Domain{})
+	})
+	mux.HandleFunc("/api/domain.create", func(w http.ResponseWriter, r *http.Request) {
+		gotPaths = append(gotPaths, r.URL.Path)
+		_ = json.NewDecoder(r.Body).Decode(&gotDomainBody)
+		_ = json.NewEncoder(w).Encode(map[string]any{"domainId": "dom-1"})
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	client, err := NewClient(ts.URL, "key")
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+
+	stack := &Stack{
+		Project: "shop",
+		Environments: []StackEnvironment{{
+			Name: "production",
+			Compose: []StackCompose{{
+				Name:    "web",
+				Content: "services: {}",
+				Env:     map[string]string{"APP_ENV": "production"},
+				Domains: []StackDomain{{Host: "shop.example.com", Path: "/", Port: 80, ServiceName: "web", CertificateType: "none"}},
+			}},
+		}},
+	}
+
+	changes, err := ApplyStack(context.Background(), client, stack)
+	if err != nil {
+		t.Fatalf("ApplyStack error: %v", err)
+	}
+
+	wantPaths := []string{"/api/project.all", "/api/project.create", "/api/compose.create", "/api/domain.byComposeId", "/api/domain.create"}
+	if len(gotPaths) != len(wantPaths) {
+		t.Fatalf("paths = %v, want %v", gotPaths, wantPaths)
+	}
+	for i := range wantPaths {
+		if gotPaths[i] != wantPaths[i] {
+			t.Errorf("paths[%d] = %q, want %q", i, gotPaths[i], wantPaths[i])
+		}
+	}
+	if gotComposeBody["environmentId"] != "env-1" {
+		t.Errorf("compose environmentId = %v, want %v", gotComposeBody["environmentId"], "env-1")
+	}
+	if gotDomainBody["composeId"] != "cmp-1" {
+		t.Errorf("domain composeId = %v, want %v", gotDomainBody["composeId"], "cmp-1")
+	}
+
+	wantActions := []ApplyAction{ActionCreated, ActionUnchanged, ActionCreated, ActionCreated}
+	if len(changes) != len(wantActions) {
+		t.Fatalf("changes = %+v, want %d entries", changes, len(wantActions))
+	}
+	for i, want := range wantActions {
+		if changes[i].Action != want {
+			t.Errorf("changes[%d] (%s %s) action = %q, want %q", i, changes[i].Resource, changes[i].Name, changes[i].Action, want)
+		}
+	}
+}
+
+func TestApplyStack_CreatesMissingEnvironment(t *testing.T) {
+	t.Helper()
+
+	var gotEnvBody, gotComposeBody map[string]any
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/project.all", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode([]Project{{
+			ProjectID:    "proj-1",
+			Name:         "shop",
+			Environments: []ProjectEnvironment{{EnvironmentID: "env-1", Name: "production"}},
+		}})
+	})
+	mux.HandleFunc("/api/environment.create", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&gotEnvBody)
+		_ = json.NewEncoder(w).Encode(map[string]any{"environmentId": "env-2"})
+	})
+	mux.HandleFunc("/api/compose.create", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&gotComposeBody)
+		_ = json.NewEncoder(w).Encode(map[string]any{"composeId": "cmp-1"})
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	client, err := NewClient(ts.URL, "key")
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+
+	stack := &Stack{
+		Project: "shop",
+		Environments: []StackEnvironment{{
+			Name:    "staging",
+			Compose: []StackCompose{{Name: "web", Content: "services: {}"}},
+		}},
+	}
+
+	changes, err := ApplyStack(context.Background(), client, stack)
+	if err != nil {
+		t.Fatalf("ApplyStack error: %v", err)
+	}
+	if gotEnvBody["projectId"] != "proj-1" || gotEnvBody["name"] != "staging" {
+		t.Errorf("environment body = %v, want projectId proj-1 and name staging", gotEnvBody)
+	}
+	if gotComposeBody["environmentId"] != "env-2" {
+		t.Errorf("compose environmentId = %v, want %v", gotComposeBody["environmentId"], "env-2")
+	}
+	if len(changes) < 2 || changes[1].Resource != "environment" || changes[1].Action != ActionCreated || changes[1].ID != "env-2" {
+		t.Errorf("changes = %+v, want environment staging created as env-2", changes)
+	}
+}
+
+func TestApplyStack_LeavesMatchingResourcesAlone(t *testing.T) {
+	t.Helper()
+
+	var writes []string
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/project.all", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode([]Project{{
+			ProjectID: "proj-1",
+			Name:      "shop",
+			Environments: []ProjectEnvironment{{
+				EnvironmentID: "env-1",
+				Name:          "production",
+				Compose:       []ProjectCompose{{ComposeID: "cmp-1", Name: "web"}},
+			}},
+		}})
+	})
+	mux.HandleFunc("/api/compose.one", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"composeId":   "cmp-1",
+			"composeFile": "services: {}",
+			"env":         "APP_ENV=production",
+		})
+	})
+	mux.HandleFunc("/api/domain.byComposeId", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode([]Domain{
+			{DomainID: "dom-1", Host: "shop.example.com", Path: "/", Port: 80, ServiceName: "web", CertificateType: "none"},
+			{DomainID: "dom-2", Host: "api.example.com", Path: "/", Port: 8080, ServiceName: "api", CertificateType: "none"},
+		})
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		writes = append(writes, r.URL.Path)
+		_ = json.NewEncoder(w).Encode(map[string]any{"domainId": "dom-2"})
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	client, err := NewClient(ts.URL, "key")
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+
+	stack := &Stack{
+		Project: "shop",
+		Environments: []StackEnvironment{{
+			Name: "production",
+			Compose: []StackCompose{{
+				Name:    "web",
+				Content: "services: {}",
+				Env:     map[string]string{"APP_ENV": "production"},
+				Domains: []StackDomain{
+					{Host: "shop.example.com", Path: "/", Port: 80, ServiceName: "web", CertificateType: "none"},
+					{Host: "api.example.com", Path: "/", Port: 8080, ServiceName: "api", CertificateType: "letsencrypt", HTTPS: true},
+				},
+			}},
+		}},
+	}
+
+	changes, err := ApplyStack(context.Background(), client, stack)
+	if err != nil {
+		t.Fatalf("ApplyStack error: %v", err)
+	}
+
+	if len(writes) != 1 || writes[0] != "/api/domain.update" {
+		t.Errorf("writes = %v, want [/api/domain.update]", writes)
+	}
+	last := changes[len(changes)-1]
+	if last.Resource != "domain" || last.ID != "dom-2" || last.Action != ActionUpdated {
+		t.Errorf("last change = %+v, want updated domain dom-2", last)
+	}
+	for _, ch := range changes[:len(changes)-1] {
+		if ch.Action != ActionUnchanged {
+			t.Errorf("%s %s action = %q, want %q", ch.Resource, ch.Name, ch.Action, ActionUnchanged)
+		}
+	}
+}
+
+func TestPlanStack_ReportsDiffsWithoutWriting(t *testing.T) {
+	t.Helper()
+
+	var writes []string
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/project.all", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode([]Project{{
+			ProjectID: "proj-1",
+			Name:      "shop",
+			Environments: []ProjectEnvironment{{
+				EnvironmentID: "env-1",
+				Name:          "production",
+				Compose:       []ProjectCompose{{ComposeID: "cmp-1", Name: "web"}},
+			}},
+		}})
+	})
+	mux.HandleFunc("/api/compose.one", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"composeId":   "cmp-1",
+			"composeFile": "services:\n  web:\n    image: nginx:1.25",
+			"env":         "APP_ENV=staging\nOLD=1",
+		})
+	})
+	mux.HandleFunc("/api/domain.byComposeId", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode([]Domain{
+			{DomainID: "dom-1", Host: "shop.example.com", Path: "/", Port: 80, ServiceName: "web", CertificateType: "none"},
+		})
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		writes = append(writes, r.URL.Path)
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	client, err := NewClient(ts.URL, "key")
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+
+	stack := &Stack{
+		Project: "shop",
+		Environments: []StackEnvironment{{
+			Name: "production",
+			Compose: []StackCompose{{
+				Name:    "web",
+				Content: "services:\n  web:\n    image: nginx:1.27",
+				Env:     map[string]string{"APP_ENV": "production"},
+				Domains: []StackDomain{
+					{Host: "shop.example.com", Path: "/", Port: 8080, ServiceName: "web", CertificateType: "none"},
+					{Host: "api.example.com", Path: "/", Port: 80, ServiceName: "api", CertificateType: "none"},
+				},
+			}},
+		}},
+	}
+
+	changes, err := PlanStack(context.Background(), client, stack)
+	if err != nil {
+		t.Fatalf("PlanStack error: %v", err)
+	}
+	if len(writes) != 0 {
+		t.Errorf("writes = %v, want none", writes)
+	}
+	if !HasDrift(changes) {
+		t.Errorf("HasDrift = false, want true")
+	}
+
+	byName := map[string]ApplyChange{}
+	for _, ch := range changes {
+		byName[ch.Resource+" "+ch.Name] = ch
+	}
+
+	cmp := byName["compose web"]
+	if cmp.Action != ActionUpdated {
+		t.Errorf("compose action = %q, want %q", cmp.Action, ActionUpdated)
+	}
+	var fields []string
+	for _, d := range cmp.Diffs {
+		fields = append(fields, d.Field)
+	}
+	wantFields := []string{"composeFile", "env.APP_ENV", "env.OLD"}
+	if len(fields) != len(wantFields) {
+		t.Fatalf("compose diff fields = %v, want %v", fields, wantFields)
+	}
+	for i := range wantFields {
+		if fields[i] != wantFields[i] {
+			t.Errorf("compose diff fields[%d] = %q, want %q", i, fields[i], wantFields[i])
+		}
+	}
+
+	dom := byName["domain shop.example.com/"]
+	if dom.Action != ActionUpdated || len(dom.Diffs) != 1 || dom.Diffs[0] != (FieldDiff{Field: "port", Current: "80", Desired: "8080"}) {
+		t.Errorf("shop domain change = %+v, want port 80 -> 8080", dom)
+	}
+	if got := byName["domain api.example.com/"]; got.Action != ActionCreated || got.ID != "" {
+		t.Errorf("api domain change = %+v, want created without ID", got)
+	}
+}
+
+func TestHasDrift_FalseWhenAllUnchanged(t *testing.T) {
+	changes := []ApplyChange{{Resource: "project", Action: ActionUnchanged}, {Resource: "compose", Action: ActionUnchanged}}
+	if HasDrift(changes) {
+		t.Errorf("HasDrift = true, want false")
+	}
+}
+
+func TestLineDiff(t *testing.T) {
+	got := LineDiff("a\nb\nc", "a\nx\nc\nd")
+	want := []string{"  a", "- b", "+ x", "  c", "+ d"}
+	if len(got) != len(want) {
+		t.Fatalf("LineDiff = %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("LineDiff[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
@@ -1,13 +1,20 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"os"
+	"slices"
+	"strconv"
 	"strings"
+	"sync/atomic"
+	"time"
 
 	"github.com/saurabh-git-dev/dokploy-cli/dokploy"
+	"gopkg.in/yaml.v3"
 
 	cli "github.com/urfave/cli/v2"
 )
@@ -17,10 +24,18 @@ var (
 	commit  = ""
 )
 
+// Exit codes other than 0 (success) and 1 (any other error), so scripts can
+// tell these outcomes apart.
+const (
+	exitDrift         = 2 // plan found differences between server and stack
+	exitDeployFailed  = 3 // a waited-for deployment finished with an error
+	exitDeployTimeout = 4 // a waited-for deployment did not finish in time
+)
+
 func main() {
 	app := &cli.App{
 		Name:  "dokploy cli",
-		Usage: "Manage Dokploy projects, compose apps, and domains",
+		Usage: "Manage Dokploy projects, compose apps, applications, and domains",
 		Version: func() string {
 			if commit == "" {
 				return version
@@ -29,39 +44,133 @@ func main() {
 		}(),
 		Flags: []cli.Flag{
 			&cli.StringFlag{
-				Name:     "url",
-				Usage:    "Dokploy base API URL (or set DOKPLOY_URL)",
-				EnvVars:  []string{"DOKPLOY_URL"},
-				Required: true,
+				Name:    "url",
+				Usage:   "Dokploy base API URL (or set DOKPLOY_URL); overrides the profile",
+				EnvVars: []string{"DOKPLOY_URL"},
+			},
+			&cli.StringFlag{
+				Name:    "key",
+				Usage:   "Dokploy API key (or set DOKPLOY_API_KEY); overrides the profile",
+				EnvVars: []string{"DOKPLOY_API_KEY"},
 			},
 			&cli.StringFlag{
-				Name:     "key",
-				Usage:    "Dokploy API key (or set DOKPLOY_API_KEY)",
-				EnvVars:  []string{"DOKPLOY_API_KEY"},
-				Required: true,
+				Name:    "profile",
+				Usage:   "Connection profile from the config file (or set DOKPLOY_PROFILE; default: the current profile)",
+				EnvVars: []string{"DOKPLOY_PROFILE"},
+			},
+			&cli.StringFlag{
+				Name:      "config",
+				Usage:     "Config file with connection profiles (or set DOKPLOY_CONFIG)",
+				EnvVars:   []string{"DOKPLOY_CONFIG"},
+				Value:     defaultConfigPath(),
+				TakesFile: true,
+			},
+			&cli.IntFlag{
+				Name:    "retries",
+				Usage:   "Times to retry a failed GET request on network errors or 429/502/503/504 responses (or set DOKPLOY_RETRIES)",
+				EnvVars: []string{"DOKPLOY_RETRIES"},
+				Value:   2,
+			},
+			&cli.DurationFlag{
+				Name:    "retry-wait",
+				Usage:   "Base delay before the first retry; doubles on each retry, with jitter, up to 10s or this delay if longer",
+				EnvVars: []string{"DOKPLOY_RETRY_WAIT"},
+				Value:   500 * time.Millisecond,
 			},
+			&cli.BoolFlag{
+				Name:  "retry-post",
+				Usage: "Also retry POST requests (create/update/deploy); may repeat an operation whose response was lost",
+			},
+			outputFlag(),
 		},
+		Before: validateOutput,
 		Commands: []*cli.Command{
 			projectCommand(),
+			envCommand(),
 			composeCommand(),
+			gitProviderCommand(),
+			appCommand(),
+			deploymentCommand(),
+			dbCommand(),
 			domainCommand(),
+			applyCommand(),
+			planCommand(),
+			profileCommand(),
+			loginCommand(),
+			logoutCommand(),
+			whoamiCommand(),
+			doctorCommand(),
 		},
 	}
 
 	if err := app.Run(os.Args); err != nil {
-		fmt.Fprintln(os.Stderr, "Error:", err)
+		printError(err)
 		os.Exit(1)
 	}
 }
 
+// printError prints err to stderr, followed by a hint when Dokploy rejected
+// the request for a reason the user can usually fix from the CLI.
+func printError(err error) {
+	fmt.Fprintln(os.Stderr, "Error:", err)
+	switch {
+	case dokploy.IsUnauthorized(err):
+		fmt.Fprintln(os.Stderr, "Hint: check the API key from --key, DOKPLOY_API_KEY, the profile, or dokploy login")
+	case dokploy.IsForbidden(err):
+		fmt.Fprintln(os.Stderr, "Hint: the API key is valid but lacks permission for this operation")
+	case dokploy.IsNotFound(err):
+		fmt.Fprintln(os.Stderr, "Hint: check the ID, and that --url points at the right Dokploy instance")
+	}
+	var ambiguous *dokploy.AmbiguousComposeError
+	if errors.As(err, &ambiguous) {
+		fmt.Fprintln(os.Stderr, "Hint: add --project and --environment, or pass --id")
+	}
+	if errors.Is(err, dokploy.ErrGitProviderNotFound) {
+		fmt.Fprintln(os.Stderr, "Hint: connect the account in Dokploy under Settings > Git, and see dokploy git-provider list")
+	}
+	if errors.Is(err, dokploy.ErrNoRollback) {
+		fmt.Fprintln(os.Stderr, "Hint: enable rollbacks for the application in Dokploy; only deployments made since then can be rolled back")
+	}
+}
+
 func newClientFromCtx(c *cli.Context) (*dokploy.Client, error) {
-	url := c.String("url")
-	key := c.String("key")
-	return dokploy.NewClient(url, key)
+	path, err := configPath(c)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := loadConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	url, key, err := resolveConnection(c.String("url"), c.String("key"), c.String("profile"), cfg, storedKeyLookup(c))
+	if err != nil {
+		return nil, err
+	}
+	return newClient(c, url, key)
+}
+
+// newClient builds a client for url and key with the global retry settings.
+func newClient(c *cli.Context, url, key string) (*dokploy.Client, error) {
+	retry := dokploy.DefaultRetryPolicy()
+	retry.MaxAttempts = c.Int("retries") + 1
+	retry.BaseDelay = c.Duration("retry-wait")
+	retry.MaxDelay = max(retry.MaxDelay, retry.BaseDelay)
+	retry.RetryPOST = c.Bool("retry-post")
+
+	return dokploy.NewClient(url, key,
+		dokploy.WithRetryPolicy(retry),
+		dokploy.WithUserAgent("dokploy-cli/"+version),
+	)
 }
 
 // PROJECT COMMANDS
 
+// projectCreateResult is the Data of project create.
+type projectCreateResult struct {
+	ProjectID     string `json:"projectId"`
+	EnvironmentID string `json:"environmentId"`
+}
+
 func projectCommand() *cli.Command {
 	return &cli.Command{
 		Name:  "project",
@@ -69,12 +178,12 @@ func projectCommand() *cli.Command {
 		Subcommands: []*cli.Command{
 			{
 				Name:  "create",
-				Usage: "Create/Get a project and its default environment",
+				Usage: "Create/Get a project and an environment, creating either if missing",
 				Flags: []cli.Flag{
 					&cli.StringFlag{Name: "name", Usage: "Project name", Required: true},
 					&cli.StringFlag{Name: "description", Usage: "Project description"},
 					&cli.StringFlag{Name: "environment", Usage: "Environment name", Value: "production"},
-					&cli.StringFlag{Name: "return", Usage: "Which ID to print (projectId/environmentId/both)", Value: "environmentId"},
+					&cli.StringFlag{Name: "return", Usage: "Which ID to print by default and with --output id (projectId/environmentId/both)", Value: "environmentId"},
 				},
 				Action: func(c *cli.Context) error {
 					client, err := newClientFromCtx(c)
@@ -82,27 +191,126 @@ func projectCommand() *cli.Command {
 						return err
 					}
 
-					var projectId, envId string
-					projectId, envId, err = dokploy.GetProject(c.Context, client, c.String("name"), c.String("environment"))
-					if projectId == "" {
+					projectId, envId, err := dokploy.GetProject(c.Context, client, c.String("name"), c.String("environment"))
+					switch {
+					case errors.Is(err, dokploy.ErrProjectNotFound):
 						projectId, envId, err = dokploy.CreateProject(c.Context, client, c.String("name"), c.String("description"), c.String("environment"))
 						if err != nil {
 							return err
 						}
+					case errors.Is(err, dokploy.ErrEnvironmentNotFound):
+						envId, err = dokploy.CreateEnvironment(c.Context, client, projectId, c.String("environment"), "")
+						if err != nil {
+							return err
+						}
+					case err != nil:
+						return err
 					}
 
+					out := output{
+						Data: projectCreateResult{ProjectID: projectId, EnvironmentID: envId},
+					}
 					mode := strings.ToLower(c.String("return"))
 					switch mode {
 					case "", "environmentid":
-						fmt.Println(envId)
+						out.IDs = []string{envId}
 					case "projectid":
-						fmt.Println(projectId)
+						out.IDs = []string{projectId}
 					case "both":
-						fmt.Printf("%s %s\n", projectId, envId)
+						out.IDs = []string{projectId, envId}
 					default:
 						return fmt.Errorf("invalid --return value %q, must be one of: projectId, environmentId, both", mode)
 					}
-					return nil
+					out.Text = strings.Join(out.IDs, " ")
+					return printOutput(c, out)
+				},
+			},
+			{
+				Name:  "list",
+				Usage: "List projects with their environments and service counts",
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "name", Usage: "Only projects whose name matches this glob (e.g. 'shop-*')"},
+				},
+				Action: func(c *cli.Context) error {
+					client, err := newClientFromCtx(c)
+					if err != nil {
+						return err
+					}
+					projects, err := dokploy.ListProjects(c.Context, client)
+					if err != nil {
+						return err
+					}
+					projects, err = dokploy.FilterProjects(projects, c.String("name"))
+					if err != nil {
+						return err
+					}
+					out := output{
+						Data:    projects,
+						Columns: []string{"PROJECT ID", "NAME", "ENVIRONMENTS", "COMPOSE", "APPS", "DATABASES"},
+						Rows:    [][]string{},
+					}
+					for _, p := range projects {
+						var envs []string
+						var compose, apps, dbs int
+						for _, env := range p.Environments {
+							envs = append(envs, env.Name)
+							compose += len(env.Compose)
+							apps += len(env.Applications)
+							dbs += len(env.Databases)
+						}
+						out.IDs = append(out.IDs, p.ProjectID)
+						out.Rows = append(out.Rows, []string{
+							p.ProjectID, p.Name, strings.Join(envs, ","),
+							strconv.Itoa(compose), strconv.Itoa(apps), strconv.Itoa(dbs),
+						})
+					}
+					return printOutput(c, out)
+				},
+			},
+			{
+				Name:  "get",
+				Usage: "Print a project with its environments, compose apps, applications and databases",
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "id", Usage: "Project ID"},
+					&cli.StringFlag{Name: "name", Usage: "Project name, or a glob matching exactly one project"},
+				},
+				Action: func(c *cli.Context) error {
+					if (c.String("id") == "") == (c.String("name") == "") {
+						return errors.New("exactly one of --id or --name is required")
+					}
+					client, err := newClientFromCtx(c)
+					if err != nil {
+						return err
+					}
+					var project *dokploy.Project
+					if id := c.String("id"); id != "" {
+						project, err = dokploy.GetProjectByID(c.Context, client, id)
+						if err != nil {
+							return err
+						}
+					} else {
+						projects, err := dokploy.ListProjects(c.Context, client)
+						if err != nil {
+							return err
+						}
+						projects, err = dokploy.FilterProjects(projects, c.String("name"))
+						if err != nil {
+							return err
+						}
+						switch len(projects) {
+						case 0:
+							return fmt.Errorf("project %q: %w", c.String("name"), dokploy.ErrProjectNotFound)
+						case 1:
+							project = &projects[0]
+						default:
+							names := make([]string, len(projects))
+							for i, p := range projects {
+								names[i] = p.Name
+							}
+							return fmt.Errorf("--name %q matches %d projects (%s); use a narrower pattern or --id", c.String("name"), len(projects), strings.Join(names, ", "))
+						}
+					}
+					return printOutput(c, projectOutput(project))
 				},
 			},
 			{
@@ -120,207 +328,2054 @@ func projectCommand() *cli.Command {
 					if err := dokploy.DeleteProject(c.Context, client, id); err != nil {
 						return err
 					}
-					fmt.Println("Deleted project", id)
-					return nil
+					return printOutput(c, actionOutput("project", id, "deleted", "Deleted project "+id))
 				},
 			},
 		},
 	}
 }
 
-// COMPOSE COMMANDS
+// projectOutput is the output of project get: the project tree as text, and
+// one table row per service.
+func projectOutput(p *dokploy.Project) output {
+	out := output{
+		Data:    p,
+		IDs:     []string{p.ProjectID},
+		Columns: []string{"ENVIRONMENT", "TYPE", "NAME", "ID", "STATUS"},
+		Rows:    [][]string{},
+	}
+	var text strings.Builder
+	fmt.Fprintf(&text, "Project %s (%s)", p.Name, p.ProjectID)
+	for _, env := range p.Environments {
+		fmt.Fprintf(&text, "\n  Environment %s (%s)", env.Name, env.EnvironmentID)
+		service := func(kind, name, id, status string) {
+			fmt.Fprintf(&text, "\n    %-11s %s (%s) %s", kind, name, id, status)
+			out.Rows = append(out.Rows, []string{env.Name, kind, name, id, status})
+		}
+		for _, cmp := range env.Compose {
+			service("compose", cmp.Name, cmp.ComposeID, cmp.ComposeStatus)
+		}
+		for _, app := range env.Applications {
+			service("application", app.Name, app.ApplicationID, app.ApplicationStatus)
+		}
+		for _, db := range env.Databases {
+			service(string(db.Type), db.Name, db.ID, db.ApplicationStatus)
+		}
+	}
+	out.Text = text.String()
+	return out
+}
 
-func composeCommand() *cli.Command {
+// ENVIRONMENT COMMANDS
+
+func envCommand() *cli.Command {
+	idFlag := &cli.StringFlag{Name: "id", Usage: "Environment ID", Required: true}
 	return &cli.Command{
-		Name:  "compose",
-		Usage: "Manage compose apps",
+		Name:  "env",
+		Usage: "Manage the environments of a project",
 		Subcommands: []*cli.Command{
 			{
-				Name:  "get",
-				Usage: "Get a compose app by ID or name",
+				Name:  "list",
+				Usage: "List the environments of a project",
 				Flags: []cli.Flag{
-					&cli.StringFlag{Name: "id", Usage: "Compose ID"},
-					&cli.StringFlag{Name: "name", Usage: "Compose name"},
+					&cli.StringFlag{Name: "project", Usage: "Project name"},
+					&cli.StringFlag{Name: "projectId", Usage: "Project ID"},
 				},
 				Action: func(c *cli.Context) error {
-					if c.String("id") == "" && c.String("name") == "" {
-						return errors.New("either --id or --name is required")
+					if (c.String("project") == "") == (c.String("projectId") == "") {
+						return errors.New("exactly one of --project or --projectId is required")
 					}
 					client, err := newClientFromCtx(c)
 					if err != nil {
 						return err
 					}
-					out, err := dokploy.GetCompose(c.Context, client, c.String("id"), c.String("name"))
+					projects, err := dokploy.ListProjects(c.Context, client)
 					if err != nil {
 						return err
 					}
-					enc := json.NewEncoder(os.Stdout)
-					enc.SetIndent("", "  ")
-					return enc.Encode(out)
+					i := slices.IndexFunc(projects, func(p dokploy.Project) bool {
+						return p.ProjectID == c.String("projectId") || (c.String("project") != "" && p.Name == c.String("project"))
+					})
+					if i < 0 {
+						return dokploy.ErrProjectNotFound
+					}
+					envs := projects[i].Environments
+					out := output{
+						Data:    envs,
+						Columns: []string{"ENVIRONMENT ID", "NAME", "COMPOSE", "APPS", "DATABASES", "DESCRIPTION"},
+						Rows:    [][]string{},
+					}
+					for _, env := range envs {
+						out.IDs = append(out.IDs, env.EnvironmentID)
+						out.Rows = append(out.Rows, []string{
+							env.EnvironmentID, env.Name,
+							strconv.Itoa(len(env.Compose)), strconv.Itoa(len(env.Applications)), strconv.Itoa(len(env.Databases)),
+							env.Description,
+						})
+					}
+					return printOutput(c, out)
 				},
 			},
 			{
 				Name:  "create",
-				Usage: "Create or update a compose app",
+				Usage: "Create an environment in a project",
 				Flags: []cli.Flag{
-					&cli.StringFlag{Name: "id", Usage: "Compose ID (for update)"},
-					&cli.StringFlag{Name: "name", Usage: "Compose name"},
-					&cli.StringFlag{Name: "environmentId", Usage: "Environment ID", Required: true},
-					&cli.StringFlag{Name: "compose-file", Usage: "Path to docker compose file", Required: true, TakesFile: true},
-					&cli.StringSliceFlag{Name: "env-vars", Usage: "Environment variables in KEY=VALUE form (repeatable)"},
+					&cli.StringFlag{Name: "projectId", Usage: "Project ID", Required: true},
+					&cli.StringFlag{Name: "name", Usage: "Environment name", Required: true},
+					&cli.StringFlag{Name: "description", Usage: "Environment description"},
 				},
 				Action: func(c *cli.Context) error {
 					client, err := newClientFromCtx(c)
 					if err != nil {
 						return err
 					}
-
-					composePath := c.String("compose-file")
-					content, err := os.ReadFile(composePath)
+					id, err := dokploy.CreateEnvironment(c.Context, client, c.String("projectId"), c.String("name"), c.String("description"))
 					if err != nil {
 						return err
 					}
-
-					envMap := map[string]string{}
-					for _, kv := range c.StringSlice("env-vars") {
-						parts := strings.SplitN(kv, "=", 2)
-						if len(parts) != 2 {
-							return fmt.Errorf("invalid env var %q, expected KEY=VALUE", kv)
-						}
-						envMap[parts[0]] = parts[1]
+					return printOutput(c, actionOutput("environment", id, "created", id))
+				},
+			},
+			{
+				Name:  "rename",
+				Usage: "Rename an environment or change its description",
+				Flags: []cli.Flag{
+					idFlag,
+					&cli.StringFlag{Name: "name", Usage: "New environment name"},
+					&cli.StringFlag{Name: "description", Usage: "New environment description"},
+				},
+				Action: func(c *cli.Context) error {
+					if c.String("name") == "" && c.String("description") == "" {
+						return errors.New("--name or --description is required")
 					}
-					id, err := dokploy.CreateOrUpdateCompose(
-						c.Context,
-						client,
-						c.String("id"),
-						c.String("name"),
-						c.String("environmentId"),
-						string(content),
-						envMap,
-					)
+					client, err := newClientFromCtx(c)
 					if err != nil {
 						return err
 					}
-					fmt.Println(id)
-					return nil
+					id := c.String("id")
+					if err := dokploy.UpdateEnvironment(c.Context, client, id, c.String("name"), c.String("description")); err != nil {
+						return err
+					}
+					return printOutput(c, actionOutput("environment", id, "updated", id))
 				},
 			},
 			{
 				Name:  "delete",
-				Usage: "Delete a compose app",
-				Flags: []cli.Flag{
-					&cli.StringFlag{Name: "id", Usage: "Compose ID", Required: true},
-					&cli.BoolFlag{Name: "delete-volumes", Usage: "Also delete associated volumes (default: true)", Value: true},
-				},
+				Usage: "Delete an environment",
+				Flags: []cli.Flag{idFlag},
 				Action: func(c *cli.Context) error {
 					client, err := newClientFromCtx(c)
 					if err != nil {
 						return err
 					}
 					id := c.String("id")
-					deleteVolumes := c.Bool("delete-volumes")
-					if err := dokploy.DeleteCompose(c.Context, client, id, deleteVolumes); err != nil {
+					if err := dokploy.DeleteEnvironment(c.Context, client, id); err != nil {
 						return err
 					}
-					fmt.Println("Deleted compose", id)
-					return nil
+					return printOutput(c, actionOutput("environment", id, "deleted", "Deleted environment "+id))
 				},
 			},
 			{
-				Name:  "deploy",
-				Usage: "Deploy a compose app",
+				Name:  "duplicate",
+				Usage: "Copy an environment and its services under a new name",
 				Flags: []cli.Flag{
-					&cli.StringFlag{Name: "id", Usage: "Compose ID", Required: true},
+					idFlag,
+					&cli.StringFlag{Name: "name", Usage: "Name of the copy", Required: true},
+					&cli.StringFlag{Name: "description", Usage: "Description of the copy"},
 				},
 				Action: func(c *cli.Context) error {
 					client, err := newClientFromCtx(c)
 					if err != nil {
 						return err
 					}
-					id := c.String("id")
-					if err := dokploy.DeployCompose(c.Context, client, id); err != nil {
+					id, err := dokploy.DuplicateEnvironment(c.Context, client, c.String("id"), c.String("name"), c.String("description"))
+					if err != nil {
 						return err
 					}
-					fmt.Println("Deployed compose", id)
-					return nil
+					return printOutput(c, actionOutput("environment", id, "created", id))
 				},
 			},
 		},
 	}
 }
 
-// DOMAIN COMMANDS
+// COMPOSE COMMANDS
 
-func domainCommand() *cli.Command {
+func composeCommand() *cli.Command {
 	return &cli.Command{
-		Name:  "domain",
-		Usage: "Manage domains",
+		Name:  "compose",
+		Usage: "Manage compose apps",
 		Subcommands: []*cli.Command{
 			{
-				Name:  "create",
-				Usage: "Create or update a domain",
+				Name:  "list",
+				Usage: "List compose apps across projects",
 				Flags: []cli.Flag{
-					&cli.StringFlag{Name: "id", Usage: "Domain ID (for update)"},
-					&cli.StringFlag{Name: "host", Usage: "Domain host", Required: true},
-					&cli.StringFlag{Name: "path", Usage: "Path", Value: "/"},
-					&cli.IntFlag{Name: "port", Usage: "Service port", Required: true},
-					&cli.StringFlag{Name: "serviceName", Usage: "Service name", Required: true},
-					&cli.StringFlag{Name: "composeId", Usage: "Compose ID", Required: true},
-					&cli.StringFlag{Name: "certificateType", Usage: "Certificate type (none/letsencrypt)", Value: "none"},
-					&cli.BoolFlag{Name: "https", Usage: "Enable HTTPS"},
+					&cli.StringFlag{Name: "project", Usage: "Only compose apps of this project (name or ID)"},
+					&cli.StringFlag{Name: "environment", Usage: "Only compose apps of this environment (name or ID)"},
 				},
 				Action: func(c *cli.Context) error {
-					certType := strings.ToLower(c.String("certificateType"))
-					switch certType {
-					case "none", "letsencrypt":
-						// ok
-					default:
-						return fmt.Errorf("invalid certificateType %q, must be one of: none, letsencrypt", certType)
+					client, err := newClientFromCtx(c)
+					if err != nil {
+						return err
+					}
+					composes, err := dokploy.ListComposes(c.Context, client, c.String("project"), c.String("environment"))
+					if err != nil {
+						return err
+					}
+					out := output{
+						Data:    composes,
+						Columns: []string{"COMPOSE ID", "NAME", "PROJECT", "ENVIRONMENT", "STATUS"},
+						Rows:    [][]string{},
+					}
+					for _, cmp := range composes {
+						out.IDs = append(out.IDs, cmp.ComposeID)
+						out.Rows = append(out.Rows, []string{cmp.ComposeID, cmp.Name, cmp.ProjectName, cmp.EnvironmentName, cmp.ComposeStatus})
+					}
+					return printOutput(c, out)
+				},
+			},
+			{
+				Name:  "get",
+				Usage: "Get a compose app by ID or name",
+				Flags: composeSelectorFlags(),
+				Action: func(c *cli.Context) error {
+					client, id, err := composeClientAndID(c)
+					if err != nil {
+						return err
+					}
+					cmp, err := dokploy.GetComposeByID(c.Context, client, id)
+					if err != nil {
+						return err
+					}
+					// Print the response as received so fields the CLI does
+					// not model are still shown.
+					return printOutput(c, output{Data: cmp.Raw, IDs: []string{cmp.ComposeID}})
+				},
+			},
+			{
+				Name:  "create",
+				Usage: "Create or update a compose app",
+				Flags: append([]cli.Flag{
+					&cli.StringFlag{Name: "id", Usage: "Compose ID (for update)"},
+					&cli.StringFlag{Name: "name", Usage: "Compose name"},
+					&cli.StringFlag{Name: "environmentId", Usage: "Environment ID", Required: true},
+					&cli.GenericFlag{Name: "compose-file", Aliases: []string{"f"}, Usage: "Path to docker compose file; repeat to merge override files, as with docker compose -f (required to create a compose app with --source raw)", TakesFile: true, Value: &pathList{}},
+					&cli.StringSliceFlag{Name: "env-vars", Usage: "Environment variables in KEY=VALUE form (repeatable); override --env-file and --env-stdin"},
+					&cli.StringSliceFlag{Name: "env-file", Usage: "Read environment variables from a .env file (repeatable; later files override earlier ones)", TakesFile: true},
+					&cli.BoolFlag{Name: "env-stdin", Usage: "Read environment variables in .env syntax from stdin"},
+					&cli.BoolFlag{Name: "env-merge", Usage: "Add or override the given variables and keep the other existing ones (requires --id)"},
+					&cli.BoolFlag{Name: "no-validate", Usage: "Upload --compose-file without checking it first"},
+				}, composeSourceFlags()...),
+				Action: func(c *cli.Context) error {
+					if c.Bool("env-merge") && c.String("id") == "" {
+						return errors.New("--env-merge requires --id")
+					}
+					source, err := composeSourceType(c)
+					if err != nil {
+						return err
 					}
-
 					client, err := newClientFromCtx(c)
 					if err != nil {
 						return err
 					}
 
-					id, err := dokploy.CreateOrUpdateDomain(
+					// Look up the source before creating anything, so a
+					// missing provider does not leave a half-configured app.
+					var src *dokploy.ComposeSource
+					if source != "" {
+						if src, err = composeSourceFromFlags(c, client, source); err != nil {
+							return err
+						}
+					}
+
+					var content []byte
+					if paths := composeFilePaths(c); len(paths) > 0 {
+						if content, err = readComposeFiles(paths); err != nil {
+							return err
+						}
+					}
+
+					env, err := readEnvFlags(c)
+					if err != nil {
+						return err
+					}
+					if c.Bool("env-merge") && env.Len() > 0 {
+						current, err := dokploy.GetComposeEnvDocument(c.Context, client, c.String("id"))
+						if err != nil {
+							return err
+						}
+						current.Merge(env)
+						env = current
+					}
+					if content != nil && !c.Bool("no-validate") {
+						if err := checkComposeFile(c, client, composeFileLabel(composeFilePaths(c)), content, env); err != nil {
+							return err
+						}
+					}
+					id, err := dokploy.CreateOrUpdateComposeWithEnv(
 						c.Context,
 						client,
 						c.String("id"),
-						c.String("host"),
-						c.String("path"),
-						c.Int("port"),
-						c.String("serviceName"),
-						c.String("composeId"),
-						certType,
-						c.Bool("https"),
+						c.String("name"),
+						c.String("environmentId"),
+						string(content),
+						env,
 					)
 					if err != nil {
 						return err
 					}
-					fmt.Println(id)
-					return nil
+					if src != nil {
+						if err := dokploy.SaveComposeSource(c.Context, client, id, *src); err != nil {
+							if c.String("id") == "" {
+								return fmt.Errorf("compose %s was created but configuring its source failed: %w", id, err)
+							}
+							return err
+						}
+					}
+					return printOutput(c, actionOutput("compose", id, savedAction(c.String("id")), id))
 				},
 			},
 			{
-				Name:  "delete",
-				Usage: "Delete a domain",
+				Name:  "validate",
+				Usage: "Check a compose file before uploading it",
 				Flags: []cli.Flag{
-					&cli.StringFlag{Name: "id", Usage: "Domain ID", Required: true},
+					&cli.GenericFlag{Name: "compose-file", Aliases: []string{"f"}, Usage: "Path to docker compose file; repeat to merge override files", Required: true, TakesFile: true, Value: &pathList{}},
+					&cli.StringFlag{Name: "id", Usage: "Compose ID whose env vars ${VAR} references are checked against"},
+					&cli.StringSliceFlag{Name: "env-vars", Usage: "Environment variables in KEY=VALUE form (repeatable); override --env-file and --env-stdin"},
+					&cli.StringSliceFlag{Name: "env-file", Usage: "Read environment variables from a .env file (repeatable; later files override earlier ones)", TakesFile: true},
+					&cli.BoolFlag{Name: "env-stdin", Usage: "Read environment variables in .env syntax from stdin"},
+					&cli.StringSliceFlag{Name: "service", Usage: "Service name a domain will route to, which must be in the file (repeatable)"},
 				},
 				Action: func(c *cli.Context) error {
-					client, err := newClientFromCtx(c)
+					paths := composeFilePaths(c)
+					path := composeFileLabel(paths)
+					content, err := readComposeFiles(paths)
 					if err != nil {
 						return err
 					}
-					id := c.String("id")
-					if err := dokploy.DeleteDomain(c.Context, client, id); err != nil {
+					env, err := readEnvFlags(c)
+					if err != nil {
 						return err
 					}
-					fmt.Println("Deleted domain", id)
+					if id := c.String("id"); id != "" {
+						client, err := newClientFromCtx(c)
+						if err != nil {
+							return err
+						}
+						current, err := dokploy.GetComposeEnvDocument(c.Context, client, id)
+						if err != nil {
+							return err
+						}
+						current.Merge(env)
+						env = current
+					}
+
+					file, problems := dokploy.ValidateComposeFile(content, env)
+					result := composeValidation{File: path, Problems: []dokploy.ComposeProblem{}}
+					if file != nil {
+						result.Services = file.Services
+						for _, name := range c.StringSlice("service") {
+							if !file.HasService(name) {
+								problems = append(problems, missingServiceProblem(name, file))
+							}
+						}
+					}
+					result.Problems = append(result.Problems, problems...)
+					result.Valid = !dokploy.HasComposeErrors(problems)
+
+					out := output{
+						Data:    result,
+						IDs:     result.Services,
+						Columns: []string{"SEVERITY", "LINE", "MESSAGE"},
+						Rows:    [][]string{},
+					}
+					var text []string
+					for _, p := range problems {
+						line := ""
+						if p.Line > 0 {
+							line = strconv.Itoa(p.Line)
+						}
+						out.Rows = append(out.Rows, []string{p.Severity, line, p.Message})
+						text = append(text, path+": "+p.String())
+					}
+					if result.Valid {
+						text = append(text, fmt.Sprintf("%s: OK (services: %s)", path, strings.Join(result.Services, ", ")))
+					}
+					out.Text = strings.Join(text, "\n")
+					if err := printOutput(c, out); err != nil {
+						return err
+					}
+					if !result.Valid {
+						return cli.Exit("", 1)
+					}
 					return nil
 				},
 			},
-		},
+			{
+				Name:  "render",
+				Usage: "Print the compose file that compose create would upload, merging override files",
+				Flags: []cli.Flag{
+					&cli.GenericFlag{Name: "compose-file", Aliases: []string{"f"}, Usage: "Path to docker compose file; repeat to merge override files", Required: true, TakesFile: true, Value: &pathList{}},
+				},
+				Action: func(c *cli.Context) error {
+					content, err := readComposeFiles(composeFilePaths(c))
+					if err != nil {
+						return err
+					}
+					if outputFormat(c) == "" {
+						_, err := os.Stdout.Write(content)
+						return err
+					}
+					var doc any
+					if err := yaml.Unmarshal(content, &doc); err != nil {
+						return err
+					}
+					return printOutput(c, output{Data: doc})
+				},
+			},
+			{
+				Name:  "delete",
+				Usage: "Delete a compose app",
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "id", Usage: "Compose ID", Required: true},
+					&cli.BoolFlag{Name: "delete-volumes", Usage: "Also delete associated volumes (default: true)", Value: true},
+				},
+				Action: func(c *cli.Context) error {
+					client, err := newClientFromCtx(c)
+					if err != nil {
+						return err
+					}
+					id := c.String("id")
+					deleteVolumes := c.Bool("delete-volumes")
+					if err := dokploy.DeleteCompose(c.Context, client, id, deleteVolumes); err != nil {
+						return err
+					}
+					return printOutput(c, actionOutput("compose", id, "deleted", "Deleted compose "+id))
+				},
+			},
+			{
+				Name:  "deploy",
+				Usage: "Deploy a compose app",
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "id", Usage: "Compose ID", Required: true},
+					&cli.BoolFlag{Name: "wait", Usage: "Wait for the deployment to finish; exits 3 if it fails and 4 on timeout"},
+					&cli.BoolFlag{Name: "follow", Usage: "Stream the build log to stdout until the deployment finishes (implies --wait)"},
+					&cli.DurationFlag{Name: "timeout", Usage: "How long --wait waits for the deployment", Value: 15 * time.Minute},
+					&cli.DurationFlag{Name: "poll-interval", Usage: "How often --wait checks the deployment status", Value: 3 * time.Second},
+				},
+				Action: func(c *cli.Context) error {
+					client, err := newClientFromCtx(c)
+					if err != nil {
+						return err
+					}
+					id := c.String("id")
+					follow := c.Bool("follow")
+					wait := c.Bool("wait") || follow
+
+					// Remember the latest deployment so the wait can tell the
+					// one triggered below apart from earlier ones.
+					var sinceID string
+					if wait {
+						prev, err := dokploy.LatestComposeDeployment(c.Context, client, id)
+						if err != nil {
+							return err
+						}
+						if prev != nil {
+							sinceID = prev.DeploymentID
+						}
+					}
+
+					if err := dokploy.DeployCompose(c.Context, client, id); err != nil {
+						return err
+					}
+					if !wait {
+						return printOutput(c, actionOutput("compose", id, "deployed", "Deployed compose "+id))
+					}
+
+					fmt.Fprintf(os.Stderr, "Waiting for compose %s to deploy...\n", id)
+					ctx, cancel := context.WithTimeout(c.Context, c.Duration("timeout"))
+					defer cancel()
+
+					var logs *logStream
+					dep, err := dokploy.WaitForComposeDeployment(ctx, client, id, sinceID, c.Duration("poll-interval"), func(d dokploy.Deployment) {
+						fmt.Fprintf(os.Stderr, "Deployment %s: %s\n", d.DeploymentID, d.Status)
+						if follow && logs == nil {
+							logs = startLogStream(c.Context, client, d.LogPath, logWriter(c))
+						}
+					})
+					if logs != nil {
+						if err := logs.Stop(); err != nil {
+							fmt.Fprintln(os.Stderr, "Warning: log stream:", err)
+						}
+					}
+					if err != nil {
+						return deployWaitError(err, c.Duration("timeout"))
+					}
+					return printOutput(c, output{
+						Data: dep,
+						IDs:  []string{dep.DeploymentID},
+						Text: fmt.Sprintf("Deployed compose %s (deployment %s)", id, dep.DeploymentID),
+					})
+				},
+			},
+			{
+				Name:  "logs",
+				Usage: "Print a deployment's build log, following it while the deployment runs",
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "id", Usage: "Compose ID (required without --deployment)"},
+					&cli.StringFlag{Name: "deployment", Usage: "Deployment ID (default: the latest deployment of --id)"},
+					&cli.DurationFlag{Name: "poll-interval", Usage: "How often to check whether a running deployment has finished", Value: 3 * time.Second},
+				},
+				Action: func(c *cli.Context) error {
+					id, want := c.String("id"), c.String("deployment")
+					if id == "" && want == "" {
+						return errors.New("pass --deployment, or --id for the latest deployment of a compose app")
+					}
+					client, err := newClientFromCtx(c)
+					if err != nil {
+						return err
+					}
+					if id == "" {
+						if id, err = deploymentComposeID(c.Context, client, want); err != nil {
+							return err
+						}
+					}
+
+					deployments, err := dokploy.ListComposeDeployments(c.Context, client, id)
+					if err != nil {
+						return err
+					}
+					var dep *dokploy.Deployment
+					for i := range deployments {
+						d := &deployments[i]
+						switch {
+						case want != "" && d.DeploymentID == want:
+							dep = d
+						case want == "" && (dep == nil || d.CreatedAt > dep.CreatedAt):
+							dep = d
+						}
+					}
+					if dep == nil {
+						if want != "" {
+							return fmt.Errorf("deployment %q not found for compose %s", want, id)
+						}
+						return fmt.Errorf("compose %s has no deployments", id)
+					}
+
+					logs := startLogStream(c.Context, client, dep.LogPath, os.Stdout)
+					if dep.Status == dokploy.StatusRunning {
+						// Only the latest deployment can be running, so waiting
+						// for the latest to finish waits for this one.
+						_, err := dokploy.WaitForComposeDeployment(c.Context, client, id, "", c.Duration("poll-interval"), nil)
+						var depErr *dokploy.DeploymentError
+						if err != nil && !errors.As(err, &depErr) {
+							_ = logs.Stop()
+							return err
+						}
+					}
+					return logs.Stop()
+				},
+			},
+			composeActionCommand(composeAction{
+				name:    "redeploy",
+				usage:   "Rebuild and redeploy a compose app from its current source",
+				action:  "redeployed",
+				done:    "Redeployed",
+				call:    dokploy.RedeployCompose,
+				deploys: true,
+			}),
+			composeActionCommand(composeAction{
+				name:    "stop",
+				usage:   "Stop a compose app's containers",
+				action:  "stopped",
+				done:    "Stopped",
+				call:    dokploy.StopCompose,
+				settled: func(status string) bool { return status == dokploy.StatusIdle },
+			}),
+			composeActionCommand(composeAction{
+				name:    "start",
+				usage:   "Start a stopped compose app's containers",
+				action:  "started",
+				done:    "Started",
+				call:    dokploy.StartCompose,
+				settled: func(status string) bool { return status == dokploy.StatusDone || status == dokploy.StatusError },
+				failed:  dokploy.StatusError,
+			}),
+			composeActionCommand(composeAction{
+				name:    "cancel-deployment",
+				usage:   "Cancel a compose app's running deployment",
+				action:  "cancelled",
+				done:    "Cancelled the deployment of",
+				call:    dokploy.CancelComposeDeployment,
+				settled: func(status string) bool { return status != dokploy.StatusRunning },
+			}),
+			composeActionCommand(composeAction{
+				name:   "clean-queues",
+				usage:  "Drop a compose app's queued deployments that have not started",
+				action: "cleaned",
+				done:   "Cleaned the deployment queue of",
+				call:   dokploy.CleanComposeQueues,
+			}),
+			composeEnvCommand(),
+		},
+	}
+}
+
+// composeAction describes a compose subcommand that calls a single
+// lifecycle endpoint.
+type composeAction struct {
+	name, usage string
+	action      string // action of the JSON result, such as "stopped"
+	done        string // start of the success message, such as "Stopped"
+	call        func(context.Context, *dokploy.Client, string) error
+	// deploys is set when the action queues a deployment, which --wait
+	// waits for like deploy --wait.
+	deploys bool
+	// settled reports whether --wait is over for a compose status. Actions
+	// with neither deploys nor settled take effect at once and have no
+	// --wait.
+	settled func(status string) bool
+	// failed is the settled status, if any, that means the action failed.
+	failed string
+}
+
+func composeActionCommand(a composeAction) *cli.Command {
+	flags := composeSelectorFlags()
+	if a.deploys || a.settled != nil {
+		flags = append(flags,
+			&cli.BoolFlag{Name: "wait", Usage: "Wait for the " + a.name + " to finish; exits 3 if it fails and 4 on timeout"},
+			&cli.DurationFlag{Name: "timeout", Usage: "How long --wait waits", Value: 15 * time.Minute},
+			&cli.DurationFlag{Name: "poll-interval", Usage: "How often --wait checks the status", Value: 3 * time.Second},
+		)
+	}
+	return &cli.Command{
+		Name:  a.name,
+		Usage: a.usage,
+		Flags: flags,
+		Action: func(c *cli.Context) error {
+			client, id, err := composeClientAndID(c)
+			if err != nil {
+				return err
+			}
+			wait := c.Bool("wait")
+
+			var sinceID string
+			if wait && a.deploys {
+				prev, err := dokploy.LatestComposeDeployment(c.Context, client, id)
+				if err != nil {
+					return err
+				}
+				if prev != nil {
+					sinceID = prev.DeploymentID
+				}
+			}
+
+			settled := a.settled
+			if wait && a.failed != "" {
+				before, err := dokploy.GetComposeByID(c.Context, client, id)
+				if err != nil {
+					return err
+				}
+				if before.ComposeStatus == a.failed {
+					// The failure is left from before the call: wait for the
+					// status to change before taking it as this one's.
+					stale := true
+					settled = func(status string) bool {
+						stale = stale && status == a.failed
+						return !stale && a.settled(status)
+					}
+				}
+			}
+
+			if err := a.call(c.Context, client, id); err != nil {
+				return err
+			}
+			if !wait {
+				return printOutput(c, actionOutput("compose", id, a.action, a.done+" compose "+id))
+			}
+
+			fmt.Fprintf(os.Stderr, "Waiting for the %s of compose %s...\n", a.name, id)
+			ctx, cancel := context.WithTimeout(c.Context, c.Duration("timeout"))
+			defer cancel()
+
+			if a.deploys {
+				dep, err := dokploy.WaitForComposeDeployment(ctx, client, id, sinceID, c.Duration("poll-interval"), func(d dokploy.Deployment) {
+					fmt.Fprintf(os.Stderr, "Deployment %s: %s\n", d.DeploymentID, d.Status)
+				})
+				if err != nil {
+					return deployWaitError(err, c.Duration("timeout"))
+				}
+				return printOutput(c, output{
+					Data: dep,
+					IDs:  []string{dep.DeploymentID},
+					Text: fmt.Sprintf("%s compose %s (deployment %s)", a.done, id, dep.DeploymentID),
+				})
+			}
+
+			status, err := dokploy.WaitForComposeStatus(ctx, client, id, c.Duration("poll-interval"), settled)
+			switch {
+			case errors.Is(err, context.DeadlineExceeded):
+				return cli.Exit(fmt.Sprintf("Error: compose %s did not finish the %s within %s", id, a.name, c.Duration("timeout")), exitDeployTimeout)
+			case err != nil:
+				return err
+			case a.failed != "" && status == a.failed:
+				return cli.Exit(fmt.Sprintf("Error: compose %s is in status %s after the %s", id, status, a.name), exitDeployFailed)
+			}
+			return printOutput(c, actionOutput("compose", id, a.action, fmt.Sprintf("%s compose %s (status %s)", a.done, id, status)))
+		},
+	}
+}
+
+// composeSourceFlags select where compose create gets the compose file from.
+func composeSourceFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{Name: "source", Usage: "Source of the compose file (" + strings.Join(dokploy.ComposeSourceTypes, "/") + "; default: git with --repo, else raw)"},
+		&cli.StringFlag{Name: "repo", Usage: "Repository URL for --source git, or owner/name for a git provider"},
+		&cli.StringFlag{Name: "branch", Usage: "Branch of --repo", Value: "main"},
+		&cli.StringFlag{Name: "compose-path", Usage: "Path of the compose file in --repo", Value: dokploy.DefaultComposePath},
+		&cli.StringFlag{Name: "ssh-key-id", Usage: "Dokploy SSH key ID for a private --repo with --source git"},
+		&cli.StringFlag{Name: "provider", Usage: "Git provider name or ID for --source github/gitlab/bitbucket/gitea (default: the only one of that type)"},
+		&cli.StringFlag{Name: "gitlab-project-id", Usage: "GitLab project ID for --source gitlab (default: looked up from --repo)"},
+	}
+}
+
+// composeSourceType validates the source flags and returns the source type
+// to save, or "" to leave the source unchanged.
+func composeSourceType(c *cli.Context) (string, error) {
+	source := c.String("source")
+	if source == "" && c.String("repo") != "" {
+		source = "git"
+	}
+	switch {
+	case source != "" && !slices.Contains(dokploy.ComposeSourceTypes, source):
+		return "", fmt.Errorf("invalid --source %q, must be one of: %s", source, strings.Join(dokploy.ComposeSourceTypes, ", "))
+	case source == "raw" || source == "":
+		if c.String("repo") != "" {
+			return "", errors.New("--repo cannot be used with --source raw")
+		}
+		if c.String("id") == "" && len(composeFilePaths(c)) == 0 {
+			return "", errors.New("--compose-file is required to create a compose app with --source raw")
+		}
+	default:
+		if c.String("repo") == "" {
+			return "", fmt.Errorf("--repo is required with --source %s", source)
+		}
+		if len(composeFilePaths(c)) > 0 {
+			return "", fmt.Errorf("--compose-file cannot be used with --source %s; use --compose-path to choose the file in the repository", source)
+		}
+	}
+	return source, nil
+}
+
+// composeSourceFromFlags builds the source given by the source flags,
+// looking up the git provider and GitLab project when needed.
+func composeSourceFromFlags(c *cli.Context, client *dokploy.Client, source string) (*dokploy.ComposeSource, error) {
+	src := dokploy.ComposeSource{
+		Type:            source,
+		Branch:          c.String("branch"),
+		ComposePath:     c.String("compose-path"),
+		GitLabProjectID: c.String("gitlab-project-id"),
+	}
+	switch source {
+	case "raw":
+	case "git":
+		src.URL = c.String("repo")
+		src.SSHKeyID = c.String("ssh-key-id")
+	default:
+		provider, err := dokploy.FindGitProvider(c.Context, client, source, c.String("provider"))
+		if err != nil {
+			return nil, err
+		}
+		src.ProviderID = provider.ProviderID
+		src.Repository = strings.Trim(c.String("repo"), "/")
+		if source == dokploy.ProviderGitLab && src.GitLabProjectID == "" {
+			repos, err := dokploy.ListProviderRepositories(c.Context, client, source, provider.ProviderID)
+			if err != nil {
+				return nil, fmt.Errorf("looking up the GitLab project (or pass --gitlab-project-id): %w", err)
+			}
+			for _, r := range repos {
+				if r.FullName == src.Repository {
+					src.GitLabProjectID = r.ID
+				}
+			}
+			if src.GitLabProjectID == "" {
+				return nil, fmt.Errorf("GitLab provider %s has no project %s; pass --gitlab-project-id", provider.Name, src.Repository)
+			}
+		}
+	}
+	return &src, nil
+}
+
+// composeSelectorFlags are the flags that select a compose app by ID, or by
+// name within an optional project and environment.
+func composeSelectorFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{Name: "id", Usage: "Compose ID"},
+		&cli.StringFlag{Name: "name", Usage: "Compose name"},
+		&cli.StringFlag{Name: "project", Usage: "Project (name or ID) to look up --name in"},
+		&cli.StringFlag{Name: "environment", Usage: "Environment (name or ID) to look up --name in"},
+	}
+}
+
+// composeClientAndID returns a client and the ID of the compose app
+// selected by composeSelectorFlags.
+func composeClientAndID(c *cli.Context) (*dokploy.Client, string, error) {
+	if c.String("id") == "" && c.String("name") == "" {
+		return nil, "", errors.New("either --id or --name is required")
+	}
+	client, err := newClientFromCtx(c)
+	if err != nil {
+		return nil, "", err
+	}
+	id := c.String("id")
+	if id == "" {
+		found, err := dokploy.FindCompose(c.Context, client, c.String("project"), c.String("environment"), c.String("name"))
+		if err != nil {
+			return nil, "", err
+		}
+		id = found.ComposeID
+	}
+	return client, id, nil
+}
+
+func composeEnvCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "env",
+		Usage: "Get, set or unset individual environment variables of a compose app",
+		Subcommands: []*cli.Command{
+			{
+				Name:      "get",
+				Usage:     "Print env vars, masked unless --reveal is set",
+				ArgsUsage: "[KEY...]",
+				Flags: append(composeSelectorFlags(),
+					&cli.BoolFlag{Name: "reveal", Usage: "Print the values instead of masking them"},
+					&cli.StringFlag{Name: "format", Usage: "Text format: dotenv or json", Value: "dotenv"},
+				),
+				Action: func(c *cli.Context) error {
+					format := c.String("format")
+					if format != "dotenv" && format != "json" {
+						return fmt.Errorf("invalid --format %q: use dotenv or json", format)
+					}
+					client, id, err := composeClientAndID(c)
+					if err != nil {
+						return err
+					}
+					env, err := dokploy.GetComposeEnvDocument(c.Context, client, id)
+					if err != nil {
+						return err
+					}
+					keys := c.Args().Slice()
+					if len(keys) == 0 {
+						keys = env.Keys()
+					}
+					// shown holds one line per variable, in the server's
+					// order, with the value that is in effect.
+					shown := &dokploy.EnvDocument{}
+					values := make(map[string]string, len(keys))
+					for _, key := range keys {
+						v, ok := env.Get(key)
+						if !ok {
+							return fmt.Errorf("env var %s is not set on compose %s", key, id)
+						}
+						if !c.Bool("reveal") {
+							v = envMask
+						}
+						shown.Append(key, v)
+						values[key] = v
+					}
+
+					out := output{
+						Data:    values,
+						IDs:     shown.Keys(),
+						Columns: []string{"KEY", "VALUE"},
+						Rows:    [][]string{},
+					}
+					for _, k := range out.IDs {
+						out.Rows = append(out.Rows, []string{k, values[k]})
+					}
+					switch {
+					case outputFormat(c) != "":
+					case format == "json":
+						return writeJSON(os.Stdout, values)
+					case len(values) == 0:
+						return nil
+					default:
+						out.Text = shown.String()
+					}
+					return printOutput(c, out)
+				},
+			},
+			{
+				Name:      "set",
+				Usage:     "Add or change env vars, keeping the others",
+				ArgsUsage: "KEY=VALUE...",
+				Flags: append(composeSelectorFlags(),
+					&cli.StringSliceFlag{Name: "env-file", Usage: "Also read env vars from a .env file (repeatable)", TakesFile: true},
+					&cli.BoolFlag{Name: "env-stdin", Usage: "Also read env vars in .env syntax from stdin"},
+				),
+				Action: func(c *cli.Context) error {
+					set, err := readEnvFlags(c)
+					if err != nil {
+						return err
+					}
+					args, err := parseEnvVars(c.Args().Slice())
+					if err != nil {
+						return err
+					}
+					set.Concat(args)
+					if set.Len() == 0 {
+						return errors.New("no env vars given: pass KEY=VALUE arguments, --env-file or --env-stdin")
+					}
+					client, id, err := composeClientAndID(c)
+					if err != nil {
+						return err
+					}
+					env, err := dokploy.GetComposeEnvDocument(c.Context, client, id)
+					if err != nil {
+						return err
+					}
+					env.Merge(set)
+					if err := dokploy.SaveComposeEnvDocument(c.Context, client, id, env); err != nil {
+						return err
+					}
+					keys := set.Keys()
+					return printOutput(c, actionOutput("compose", id, "updated", fmt.Sprintf("Set %s on compose %s", strings.Join(keys, ", "), id)))
+				},
+			},
+			{
+				Name:      "unset",
+				Usage:     "Remove env vars, keeping the others",
+				ArgsUsage: "KEY...",
+				Flags:     composeSelectorFlags(),
+				Action: func(c *cli.Context) error {
+					keys := c.Args().Slice()
+					if len(keys) == 0 {
+						return errors.New("no env vars given: pass the KEYs to remove")
+					}
+					client, id, err := composeClientAndID(c)
+					if err != nil {
+						return err
+					}
+					env, err := dokploy.GetComposeEnvDocument(c.Context, client, id)
+					if err != nil {
+						return err
+					}
+					var removed []string
+					for _, key := range keys {
+						if env.Unset(key) {
+							removed = append(removed, key)
+						}
+					}
+					if len(removed) == 0 {
+						return printOutput(c, actionOutput("compose", id, "unchanged", "None of the env vars are set on compose "+id))
+					}
+					if err := dokploy.SaveComposeEnvDocument(c.Context, client, id, env); err != nil {
+						return err
+					}
+					return printOutput(c, actionOutput("compose", id, "updated", fmt.Sprintf("Unset %s on compose %s", strings.Join(removed, ", "), id)))
+				},
+			},
+		},
+	}
+}
+
+// deploymentComposeID returns the ID of the compose app a deployment
+// belongs to. Dokploy cannot look a deployment up by its ID, so this
+// searches the deployments of every compose app.
+func deploymentComposeID(ctx context.Context, client *dokploy.Client, deploymentID string) (string, error) {
+	projects, err := dokploy.ListProjects(ctx, client)
+	if err != nil {
+		return "", err
+	}
+	for _, p := range projects {
+		for _, env := range p.Environments {
+			for _, cmp := range env.Compose {
+				deployments, err := dokploy.ListComposeDeployments(ctx, client, cmp.ComposeID)
+				if err != nil {
+					return "", err
+				}
+				for _, d := range deployments {
+					if d.DeploymentID == deploymentID {
+						return cmp.ComposeID, nil
+					}
+				}
+			}
+		}
+	}
+	return "", fmt.Errorf("deployment %q not found in any compose app", deploymentID)
+}
+
+func gitProviderCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "git-provider",
+		Usage: "List the GitHub, GitLab, Bitbucket and Gitea accounts connected to Dokploy",
+		Subcommands: []*cli.Command{
+			{
+				Name:  "list",
+				Usage: "List git providers",
+				Action: func(c *cli.Context) error {
+					client, err := newClientFromCtx(c)
+					if err != nil {
+						return err
+					}
+					providers, err := dokploy.ListGitProviders(c.Context, client)
+					if err != nil {
+						return err
+					}
+					out := output{
+						Data:    providers,
+						Columns: []string{"PROVIDER ID", "TYPE", "NAME", "GIT PROVIDER ID"},
+						Rows:    [][]string{},
+					}
+					for _, p := range providers {
+						out.IDs = append(out.IDs, p.ProviderID)
+						out.Rows = append(out.Rows, []string{p.ProviderID, p.Type, p.Name, p.ID})
+					}
+					return printOutput(c, out)
+				},
+			},
+			{
+				Name:  "repos",
+				Usage: "List the repositories of a GitHub or GitLab provider",
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "type", Usage: "Provider type (github/gitlab)", Required: true},
+					&cli.StringFlag{Name: "provider", Usage: "Provider name or ID (default: the only one of that type)"},
+				},
+				Action: func(c *cli.Context) error {
+					client, err := newClientFromCtx(c)
+					if err != nil {
+						return err
+					}
+					provider, err := dokploy.FindGitProvider(c.Context, client, c.String("type"), c.String("provider"))
+					if err != nil {
+						return err
+					}
+					repos, err := dokploy.ListProviderRepositories(c.Context, client, provider.Type, provider.ProviderID)
+					if err != nil {
+						return err
+					}
+					out := output{
+						Data:    repos,
+						Columns: []string{"REPOSITORY", "PROJECT ID"},
+						Rows:    [][]string{},
+					}
+					for _, r := range repos {
+						out.IDs = append(out.IDs, r.FullName)
+						out.Rows = append(out.Rows, []string{r.FullName, r.ID})
+					}
+					return printOutput(c, out)
+				},
+			},
+		},
+	}
+}
+
+// parseEnvVars parses repeated --env-vars KEY=VALUE flags, keeping their
+// order and any repeated keys.
+func parseEnvVars(kvs []string) (*dokploy.EnvDocument, error) {
+	env := &dokploy.EnvDocument{}
+	for _, kv := range kvs {
+		parts := strings.SplitN(kv, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid env var %q, expected KEY=VALUE", kv)
+		}
+		env.Append(parts[0], parts[1])
+	}
+	return env, nil
+}
+
+// readEnvFlags collects the env vars given by --env-file, --env-stdin and
+// --env-vars, in that order. They are kept as written, with comments and
+// repeated keys, so later definitions override earlier ones where Dokploy
+// reads them. ${VAR} in the files and stdin expands to variables defined
+// before it, then to the CLI's own environment.
+func readEnvFlags(c *cli.Context) (*dokploy.EnvDocument, error) {
+	env := &dokploy.EnvDocument{}
+	lookup := func(name string) (string, bool) {
+		if v, ok := env.Get(name); ok {
+			return v, true
+		}
+		return os.LookupEnv(name)
+	}
+	add := func(source string, text []byte) error {
+		doc, err := dokploy.ParseEnvDocument(string(text), lookup)
+		if err != nil {
+			return fmt.Errorf("%s: %w", source, err)
+		}
+		env.Concat(doc)
+		return nil
+	}
+
+	for _, path := range c.StringSlice("env-file") {
+		text, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if err := add(path, text); err != nil {
+			return nil, err
+		}
+	}
+	if c.Bool("env-stdin") {
+		text, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return nil, err
+		}
+		if err := add("stdin", text); err != nil {
+			return nil, err
+		}
+	}
+	vars, err := parseEnvVars(c.StringSlice("env-vars"))
+	if err != nil {
+		return nil, err
+	}
+	env.Concat(vars)
+	return env, nil
+}
+
+// pathList is the value of a repeatable path flag. Unlike cli.StringSlice,
+// it does not split values on commas, which are valid in file names.
+type pathList []string
+
+// pathListSerialized starts a pathList serialized by urfave/cli to copy
+// it between a flag's aliases. No argument can contain a NUL byte.
+const pathListSerialized = "\x00paths:"
+
+func (p *pathList) Set(value string) error {
+	if s, ok := strings.CutPrefix(value, pathListSerialized); ok {
+		return json.Unmarshal([]byte(s), p)
+	}
+	*p = append(*p, value)
+	return nil
+}
+
+func (p *pathList) String() string {
+	return strings.Join(*p, ", ")
+}
+
+func (p *pathList) Serialize() string {
+	b, _ := json.Marshal(*p)
+	return pathListSerialized + string(b)
+}
+
+// composeFilePaths returns the --compose-file paths, in the order given.
+func composeFilePaths(c *cli.Context) []string {
+	if p, ok := c.Generic("compose-file").(*pathList); ok {
+		return *p
+	}
+	return nil
+}
+
+// readComposeFiles reads the --compose-file paths and merges them into the
+// single compose file Dokploy stores.
+func readComposeFiles(paths []string) ([]byte, error) {
+	files := make([]dokploy.NamedComposeFile, 0, len(paths))
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, dokploy.NamedComposeFile{Name: path, Content: content})
+	}
+	return dokploy.MergeComposeFiles(files)
+}
+
+// composeFileLabel names the compose file merged from paths in messages.
+func composeFileLabel(paths []string) string {
+	return strings.Join(paths, "+")
+}
+
+// composeValidation is the output of compose validate.
+type composeValidation struct {
+	File     string                   `json:"file"`
+	Valid    bool                     `json:"valid"`
+	Services []string                 `json:"services"`
+	Problems []dokploy.ComposeProblem `json:"problems"`
+}
+
+// missingServiceProblem reports a domain service name that the compose
+// file does not define. Dokploy accepts the domain, but it routes nowhere.
+func missingServiceProblem(name string, file *dokploy.ComposeFile) dokploy.ComposeProblem {
+	return dokploy.ComposeProblem{
+		Severity: dokploy.SeverityWarning,
+		Message:  fmt.Sprintf("no service %q for a domain to route to (services: %s)", name, strings.Join(file.Services, ", ")),
+	}
+}
+
+// checkComposeFile validates a compose file before it is uploaded, against
+// the env vars it will be deployed with: env, or the compose app's current
+// ones when env is empty and an existing app is updated. Warnings are
+// printed to stderr; errors stop the upload.
+func checkComposeFile(c *cli.Context, client *dokploy.Client, path string, content []byte, env *dokploy.EnvDocument) error {
+	if id := c.String("id"); id != "" && env.Len() == 0 {
+		current, err := dokploy.GetComposeEnvDocument(c.Context, client, id)
+		if err != nil {
+			return err
+		}
+		env = current
+	}
+	_, problems := dokploy.ValidateComposeFile(content, env)
+	for _, p := range problems {
+		fmt.Fprintf(os.Stderr, "%s: %s\n", path, p)
+	}
+	if dokploy.HasComposeErrors(problems) {
+		return fmt.Errorf("%s is not a valid compose file; fix it, or pass --no-validate to upload it anyway", path)
+	}
+	return nil
+}
+
+// logStream copies a deployment log to a writer in the background.
+type logStream struct {
+	w         io.Writer
+	cancel    context.CancelFunc
+	done      chan error
+	lastWrite atomic.Int64 // unix nanoseconds of the last write, 0 before the first
+}
+
+// startLogStream starts streaming the log at logPath to w.
+func startLogStream(ctx context.Context, client *dokploy.Client, logPath string, w io.Writer) *logStream {
+	ctx, cancel := context.WithCancel(ctx)
+	s := &logStream{w: w, cancel: cancel, done: make(chan error, 1)}
+	go func() {
+		s.done <- dokploy.StreamDeploymentLogs(ctx, client, logPath, s)
+	}()
+	return s
+}
+
+func (s *logStream) Write(p []byte) (int, error) {
+	s.lastWrite.Store(time.Now().UnixNano())
+	return s.w.Write(p)
+}
+
+// logWriter is where deploy --follow streams the build log: stdout, unless
+// --output is set and stdout is reserved for the command's result.
+func logWriter(c *cli.Context) io.Writer {
+	if outputFormat(c) != "" {
+		return os.Stderr
+	}
+	return os.Stdout
+}
+
+// savedAction is the action of a create-or-update command: updated when it
+// was given the ID of an existing resource.
+func savedAction(id string) string {
+	if id != "" {
+		return "updated"
+	}
+	return "created"
+}
+
+// Stop ends the stream once it has gone quiet. Dokploy keeps tailing a log
+// after its deployment finishes, and the last lines may still be in flight
+// when the deployment is reported done.
+func (s *logStream) Stop() error {
+	const (
+		quiet     = time.Second
+		firstByte = 5 * time.Second
+		maxDrain  = 10 * time.Second
+	)
+	start := time.Now()
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	for time.Since(start) < maxDrain {
+		last := s.lastWrite.Load()
+		if last == 0 && time.Since(start) >= firstByte {
+			break
+		}
+		if last != 0 && time.Since(time.Unix(0, last)) >= quiet {
+			break
+		}
+		select {
+		case err := <-s.done:
+			s.cancel()
+			return err
+		case <-ticker.C:
+		}
+	}
+	s.cancel()
+	return <-s.done
+}
+
+// deployWaitError maps a failed wait to the exit code scripts can act on:
+// exitDeployFailed when the deployment failed and exitDeployTimeout when it
+// did not finish in time.
+func deployWaitError(err error, timeout time.Duration) error {
+	var depErr *dokploy.DeploymentError
+	switch {
+	case errors.As(err, &depErr):
+		return cli.Exit("Error: "+err.Error(), exitDeployFailed)
+	case errors.Is(err, context.DeadlineExceeded):
+		return cli.Exit(fmt.Sprintf("Error: deployment did not finish within %s", timeout), exitDeployTimeout)
+	}
+	return err
+}
+
+// APPLICATION COMMANDS
+
+func appCommand() *cli.Command {
+	idFlag := &cli.StringFlag{Name: "id", Usage: "Application ID", Required: true}
+	return &cli.Command{
+		Name:  "app",
+		Usage: "Manage applications built from Git or Docker images",
+		Subcommands: []*cli.Command{
+			{
+				Name:  "get",
+				Usage: "Get an application by ID",
+				Flags: []cli.Flag{idFlag},
+				Action: func(c *cli.Context) error {
+					client, err := newClientFromCtx(c)
+					if err != nil {
+						return err
+					}
+					out, err := dokploy.GetApplication(c.Context, client, c.String("id"))
+					if err != nil {
+						return err
+					}
+					return printOutput(c, output{Data: out, IDs: []string{out.ApplicationID}})
+				},
+			},
+			{
+				Name:  "create",
+				Usage: "Create an application and configure its source and build",
+				Flags: append([]cli.Flag{
+					&cli.StringFlag{Name: "name", Usage: "Application name", Required: true},
+					&cli.StringFlag{Name: "environmentId", Usage: "Environment ID", Required: true},
+					&cli.StringFlag{Name: "description", Usage: "Application description"},
+				}, appSettingsFlags()...),
+				Action: func(c *cli.Context) error {
+					if err := validateAppSettings(c); err != nil {
+						return err
+					}
+					client, err := newClientFromCtx(c)
+					if err != nil {
+						return err
+					}
+					id, err := dokploy.CreateApplication(c.Context, client, c.String("name"), c.String("description"), c.String("environmentId"))
+					if err != nil {
+						return err
+					}
+					if err := saveAppSettings(c, client, id); err != nil {
+						return fmt.Errorf("application %s was created but configuring it failed: %w", id, err)
+					}
+					return printOutput(c, actionOutput("application", id, "created", id))
+				},
+			},
+			{
+				Name:  "update",
+				Usage: "Update an application's name, source, build or env vars",
+				Flags: append([]cli.Flag{
+					idFlag,
+					&cli.StringFlag{Name: "name", Usage: "Application name"},
+					&cli.StringFlag{Name: "description", Usage: "Application description"},
+				}, appSettingsFlags()...),
+				Action: func(c *cli.Context) error {
+					if err := validateAppSettings(c); err != nil {
+						return err
+					}
+					client, err := newClientFromCtx(c)
+					if err != nil {
+						return err
+					}
+					id := c.String("id")
+					if c.String("name") != "" || c.String("description") != "" {
+						if err := dokploy.UpdateApplication(c.Context, client, id, c.String("name"), c.String("description")); err != nil {
+							return err
+						}
+					}
+					if err := saveAppSettings(c, client, id); err != nil {
+						return err
+					}
+					return printOutput(c, actionOutput("application", id, "updated", id))
+				},
+			},
+			appActionCommand("deploy", "Deploy an application", "Deployed", dokploy.DeployApplication),
+			appActionCommand("redeploy", "Rebuild and redeploy an application from its current source", "Redeployed", dokploy.RedeployApplication),
+			appActionCommand("stop", "Stop a running application", "Stopped", dokploy.StopApplication),
+			appActionCommand("start", "Start a stopped application", "Started", dokploy.StartApplication),
+			appActionCommand("delete", "Delete an application", "Deleted", dokploy.DeleteApplication),
+		},
+	}
+}
+
+func appActionCommand(name, usage, done string, action func(context.Context, *dokploy.Client, string) error) *cli.Command {
+	return &cli.Command{
+		Name:  name,
+		Usage: usage,
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "id", Usage: "Application ID", Required: true},
+		},
+		Action: func(c *cli.Context) error {
+			client, err := newClientFromCtx(c)
+			if err != nil {
+				return err
+			}
+			id := c.String("id")
+			if err := action(c.Context, client, id); err != nil {
+				return err
+			}
+			return printOutput(c, actionOutput("application", id, strings.ToLower(done), done+" application "+id))
+		},
+	}
+}
+
+// appSettingsFlags are the source, build and env flags shared by app create
+// and app update. Settings whose flags are not given are left unchanged.
+func appSettingsFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{Name: "build-type", Usage: "Build type (" + strings.Join(dokploy.ApplicationBuildTypes, "/") + ")"},
+		&cli.StringFlag{Name: "dockerfile", Usage: "Dockerfile path, for --build-type dockerfile", Value: "Dockerfile"},
+		&cli.StringFlag{Name: "docker-context-path", Usage: "Docker build context, for --build-type dockerfile"},
+		&cli.StringFlag{Name: "docker-build-stage", Usage: "Docker build stage (target), for --build-type dockerfile"},
+		&cli.StringFlag{Name: "publish-directory", Usage: "Directory to serve, for --build-type static"},
+		&cli.StringFlag{Name: "docker-image", Usage: "Run this Docker image (sets the source to docker)"},
+		&cli.StringFlag{Name: "registry-url", Usage: "Registry URL for --docker-image"},
+		&cli.StringFlag{Name: "registry-username", Usage: "Registry username for --docker-image"},
+		&cli.StringFlag{Name: "registry-password", Usage: "Registry password for --docker-image"},
+		&cli.StringFlag{Name: "repo", Usage: "Git repository URL to build from (sets the source to git)"},
+		&cli.StringFlag{Name: "branch", Usage: "Git branch for --repo", Value: "main"},
+		&cli.StringFlag{Name: "build-path", Usage: "Path inside the repository to build, for --repo", Value: "/"},
+		&cli.StringFlag{Name: "ssh-key-id", Usage: "Dokploy SSH key ID for a private --repo"},
+		&cli.StringSliceFlag{Name: "env-vars", Usage: "Environment variables in KEY=VALUE form (repeatable); replaces all existing env vars"},
+	}
+}
+
+func validateAppSettings(c *cli.Context) error {
+	if c.String("docker-image") != "" && c.String("repo") != "" {
+		return errors.New("--docker-image and --repo are mutually exclusive")
+	}
+	if bt := c.String("build-type"); bt != "" && !slices.Contains(dokploy.ApplicationBuildTypes, bt) {
+		return fmt.Errorf("invalid --build-type %q, must be one of: %s", bt, strings.Join(dokploy.ApplicationBuildTypes, ", "))
+	}
+	return nil
+}
+
+// saveAppSettings saves the source, build and env settings given on the
+// command line to application id.
+func saveAppSettings(c *cli.Context, client *dokploy.Client, id string) error {
+	switch {
+	case c.String("docker-image") != "":
+		src := dokploy.DockerSource{
+			Image:       c.String("docker-image"),
+			RegistryURL: c.String("registry-url"),
+			Username:    c.String("registry-username"),
+			Password:    c.String("registry-password"),
+		}
+		if err := dokploy.SaveApplicationDockerSource(c.Context, client, id, src); err != nil {
+			return err
+		}
+	case c.String("repo") != "":
+		src := dokploy.GitSource{
+			URL:       c.String("repo"),
+			Branch:    c.String("branch"),
+			BuildPath: c.String("build-path"),
+			SSHKeyID:  c.String("ssh-key-id"),
+		}
+		if err := dokploy.SaveApplicationGitSource(c.Context, client, id, src); err != nil {
+			return err
+		}
+	}
+
+	if bt := c.String("build-type"); bt != "" {
+		build := dokploy.ApplicationBuild{
+			BuildType:         bt,
+			Dockerfile:        c.String("dockerfile"),
+			DockerContextPath: c.String("docker-context-path"),
+			DockerBuildStage:  c.String("docker-build-stage"),
+			PublishDirectory:  c.String("publish-directory"),
+		}
+		if err := dokploy.SaveApplicationBuildType(c.Context, client, id, build); err != nil {
+			return err
+		}
+	}
+
+	if c.IsSet("env-vars") {
+		env, err := parseEnvVars(c.StringSlice("env-vars"))
+		if err != nil {
+			return err
+		}
+		if err := dokploy.SaveApplicationEnvDocument(c.Context, client, id, env); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DEPLOYMENT COMMANDS
+
+func deploymentCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "deployment",
+		Usage: "Show the deployment history of compose apps and applications, and roll back",
+		Subcommands: []*cli.Command{
+			{
+				Name:  "list",
+				Usage: "List the deployments of a compose app or application, newest first",
+				Flags: deploymentOwnerFlags(),
+				Action: func(c *cli.Context) error {
+					client, err := newClientFromCtx(c)
+					if err != nil {
+						return err
+					}
+					deployments, err := listDeployments(c, client)
+					if err != nil {
+						return err
+					}
+					out := output{
+						Data:    deployments,
+						Columns: []string{"DEPLOYMENT ID", "STATUS", "TITLE", "CREATED", "FINISHED", "DURATION"},
+						Rows:    [][]string{},
+					}
+					for _, d := range deployments {
+						out.IDs = append(out.IDs, d.DeploymentID)
+						out.Rows = append(out.Rows, []string{d.DeploymentID, d.Status, d.Title, d.CreatedAt, d.FinishedAt, formatDuration(d.Duration())})
+					}
+					return printOutput(c, out)
+				},
+			},
+			{
+				Name:      "show",
+				Usage:     "Show a deployment, including the path of its build log",
+				ArgsUsage: "DEPLOYMENT_ID",
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "compose-id", Usage: "Compose ID the deployment belongs to (default: search every compose app and application)"},
+					&cli.StringFlag{Name: "app-id", Usage: "Application ID the deployment belongs to"},
+				},
+				Action: func(c *cli.Context) error {
+					if c.NArg() != 1 {
+						return fmt.Errorf("expected one deployment ID after the flags, got %d arguments", c.NArg())
+					}
+					client, err := newClientFromCtx(c)
+					if err != nil {
+						return err
+					}
+					var d *dokploy.Deployment
+					if c.String("compose-id") == "" && c.String("app-id") == "" {
+						d, err = dokploy.GetDeployment(c.Context, client, c.Args().First())
+					} else {
+						var deployments []dokploy.Deployment
+						deployments, err = listDeployments(c, client)
+						if err == nil {
+							d, err = dokploy.FindDeployment(deployments, c.Args().First())
+						}
+					}
+					if err != nil {
+						return err
+					}
+					return printOutput(c, output{
+						Data:    d,
+						IDs:     []string{d.DeploymentID},
+						Columns: []string{"FIELD", "VALUE"},
+						Rows: [][]string{
+							{"ID", d.DeploymentID},
+							{"STATUS", d.Status},
+							{"TITLE", d.Title},
+							{"DESCRIPTION", d.Description},
+							{"CREATED", d.CreatedAt},
+							{"STARTED", d.StartedAt},
+							{"FINISHED", d.FinishedAt},
+							{"DURATION", formatDuration(d.Duration())},
+							{"ERROR", d.ErrorMessage},
+							{"LOG PATH", d.LogPath},
+							{"ROLLBACK ID", d.RollbackID},
+						},
+					})
+				},
+			},
+			{
+				Name:  "rollback",
+				Usage: "Redeploy an application with the image of an earlier deployment",
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "app-id", Usage: "Application ID", Required: true},
+					&cli.StringFlag{Name: "to", Usage: "ID of the deployment to roll back to", Required: true},
+				},
+				Action: func(c *cli.Context) error {
+					client, err := newClientFromCtx(c)
+					if err != nil {
+						return err
+					}
+					appID := c.String("app-id")
+					d, err := dokploy.RollbackToDeployment(c.Context, client, appID, c.String("to"))
+					if err != nil {
+						return err
+					}
+					return printOutput(c, actionOutput("application", appID, "rolled back",
+						fmt.Sprintf("Rolled back application %s to deployment %s", appID, d.DeploymentID)))
+				},
+			},
+		},
+	}
+}
+
+// deploymentOwnerFlags select the compose app or application whose
+// deployments a command reads; Dokploy lists deployments per owner.
+func deploymentOwnerFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{Name: "compose-id", Usage: "Compose ID"},
+		&cli.StringFlag{Name: "app-id", Usage: "Application ID"},
+	}
+}
+
+// listDeployments lists the deployments of the --compose-id or --app-id
+// owner, newest first.
+func listDeployments(c *cli.Context, client *dokploy.Client) ([]dokploy.Deployment, error) {
+	composeID, appID := c.String("compose-id"), c.String("app-id")
+	var deployments []dokploy.Deployment
+	var err error
+	switch {
+	case (composeID == "") == (appID == ""):
+		return nil, fmt.Errorf("pass exactly one of --compose-id and --app-id")
+	case composeID != "":
+		deployments, err = dokploy.ListComposeDeployments(c.Context, client, composeID)
+	default:
+		deployments, err = dokploy.ListApplicationDeployments(c.Context, client, appID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	// createdAt is an ISO 8601 timestamp, so it sorts as a string.
+	slices.SortStableFunc(deployments, func(a, b dokploy.Deployment) int {
+		return strings.Compare(b.CreatedAt, a.CreatedAt)
+	})
+	return deployments, nil
+}
+
+// formatDuration formats a deployment duration to the second, or as empty
+// for a deployment that has not finished.
+func formatDuration(d time.Duration) string {
+	if d <= 0 {
+		return ""
+	}
+	return d.Round(time.Second).String()
+}
+
+// DATABASE COMMANDS
+
+const dbTypeUsage = "Database type (postgres/mysql/mariadb/mongo/redis)"
+
+// dbCreateResult is the Data of db create. The passwords are only set when
+// they were generated, since they cannot be read back otherwise.
+type dbCreateResult struct {
+	Resource     string `json:"resource"`
+	ID           string `json:"id"`
+	Action       string `json:"action"`
+	Password     string `json:"password,omitempty"`
+	RootPassword string `json:"rootPassword,omitempty"`
+}
+
+// passwordResetResult is the Data of db reset-password. Password is only
+// set when it was generated.
+type passwordResetResult struct {
+	Resource string `json:"resource"`
+	ID       string `json:"id"`
+	Action   string `json:"action"`
+	Password string `json:"password,omitempty"`
+}
+
+// dbIDFlags are the flags identifying an existing database.
+func dbIDFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{Name: "type", Usage: dbTypeUsage, Required: true},
+		&cli.StringFlag{Name: "id", Usage: "Database ID", Required: true},
+	}
+}
+
+func dbCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "db",
+		Usage: "Manage Postgres, MySQL, MariaDB, MongoDB and Redis databases",
+		Subcommands: []*cli.Command{
+			{
+				Name:  "create",
+				Usage: "Create a database in an environment",
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "type", Usage: dbTypeUsage, Required: true},
+					&cli.StringFlag{Name: "name", Usage: "Database service name", Required: true},
+					&cli.StringFlag{Name: "environmentId", Usage: "Environment ID", Required: true},
+					&cli.StringFlag{Name: "description", Usage: "Description"},
+					&cli.StringFlag{Name: "image", Usage: "Docker image (default: the engine's official image)"},
+					&cli.StringFlag{Name: "database-name", Usage: "Database to create (postgres/mysql/mariadb; default: derived from --name)"},
+					&cli.StringFlag{Name: "user", Usage: "Database user (postgres/mysql/mariadb/mongo; default: derived from --name)"},
+					&cli.StringFlag{Name: "password", Usage: "Database password (default: generated)"},
+					&cli.StringFlag{Name: "root-password", Usage: "Root password (mysql/mariadb; default: generated)"},
+				},
+				Action: func(c *cli.Context) error {
+					dbType, err := dokploy.ParseDatabaseType(c.String("type"))
+					if err != nil {
+						return err
+					}
+					client, err := newClientFromCtx(c)
+					if err != nil {
+						return err
+					}
+					id, cfg, err := dokploy.CreateDatabase(c.Context, client, dbType, dokploy.DatabaseConfig{
+						Name:          c.String("name"),
+						Description:   c.String("description"),
+						EnvironmentID: c.String("environmentId"),
+						DockerImage:   c.String("image"),
+						DatabaseName:  c.String("database-name"),
+						DatabaseUser:  c.String("user"),
+						Password:      c.String("password"),
+						RootPassword:  c.String("root-password"),
+					})
+					if err != nil {
+						return err
+					}
+					out := dbCreateResult{Resource: string(dbType), ID: id, Action: "created"}
+					text := []string{id}
+					if c.String("password") == "" {
+						out.Password = cfg.Password
+						text = append(text, "password: "+cfg.Password)
+					}
+					if c.String("root-password") == "" && cfg.RootPassword != "" {
+						out.RootPassword = cfg.RootPassword
+						text = append(text, "root-password: "+cfg.RootPassword)
+					}
+					return printOutput(c, output{Data: out, IDs: []string{id}, Text: strings.Join(text, "\n")})
+				},
+			},
+			{
+				Name:  "get",
+				Usage: "Get a database by type and ID",
+				Flags: dbIDFlags(),
+				Action: func(c *cli.Context) error {
+					dbType, err := dokploy.ParseDatabaseType(c.String("type"))
+					if err != nil {
+						return err
+					}
+					client, err := newClientFromCtx(c)
+					if err != nil {
+						return err
+					}
+					out, err := dokploy.GetDatabase(c.Context, client, dbType, c.String("id"))
+					if err != nil {
+						return err
+					}
+					return printOutput(c, output{Data: out, IDs: []string{out.ID}})
+				},
+			},
+			dbActionCommand("deploy", "Deploy a database", "Deployed", dokploy.DeployDatabase),
+			dbActionCommand("stop", "Stop a database", "Stopped", dokploy.StopDatabase),
+			dbActionCommand("start", "Start a stopped database", "Started", dokploy.StartDatabase),
+			dbActionCommand("delete", "Delete a database", "Deleted", dokploy.DeleteDatabase),
+			{
+				Name:  "reset-password",
+				Usage: "Set a new Redis password; prints it when generated (takes effect on the next deploy)",
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "type", Usage: "Database type; only redis is supported", Value: string(dokploy.Redis)},
+					&cli.StringFlag{Name: "id", Usage: "Database ID", Required: true},
+					&cli.StringFlag{Name: "password", Usage: "New password (default: generated)"},
+				},
+				Action: func(c *cli.Context) error {
+					dbType, err := dokploy.ParseDatabaseType(c.String("type"))
+					if err != nil {
+						return err
+					}
+					if !dbType.SupportsPasswordReset() {
+						return fmt.Errorf("reset-password only supports redis: %s only reads the password when its data volume is first initialised; change it inside the database, then in Dokploy", dbType)
+					}
+					client, err := newClientFromCtx(c)
+					if err != nil {
+						return err
+					}
+					id := c.String("id")
+					password, err := dokploy.ResetDatabasePassword(c.Context, client, dbType, id, c.String("password"))
+					if err != nil {
+						return err
+					}
+					out := passwordResetResult{Resource: string(dbType), ID: id, Action: "password-reset"}
+					text := "Reset password of " + string(dbType) + " " + id
+					if c.String("password") == "" {
+						out.Password = password
+						text = password
+					}
+					return printOutput(c, output{Data: out, IDs: []string{id}, Text: text})
+				},
+			},
+		},
+	}
+}
+
+func dbActionCommand(name, usage, done string, action func(context.Context, *dokploy.Client, dokploy.DatabaseType, string) error) *cli.Command {
+	return &cli.Command{
+		Name:  name,
+		Usage: usage,
+		Flags: dbIDFlags(),
+		Action: func(c *cli.Context) error {
+			dbType, err := dokploy.ParseDatabaseType(c.String("type"))
+			if err != nil {
+				return err
+			}
+			client, err := newClientFromCtx(c)
+			if err != nil {
+				return err
+			}
+			id := c.String("id")
+			if err := action(c.Context, client, dbType, id); err != nil {
+				return err
+			}
+			return printOutput(c, actionOutput(string(dbType), id, strings.ToLower(done), fmt.Sprintf("%s %s %s", done, dbType, id)))
+		},
+	}
+}
+
+// DOMAIN COMMANDS
+
+func domainCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "domain",
+		Usage: "Manage domains",
+		Subcommands: []*cli.Command{
+			{
+				Name:  "create",
+				Usage: "Create or update a domain",
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "id", Usage: "Domain ID (for update)"},
+					&cli.StringFlag{Name: "host", Usage: "Domain host", Required: true},
+					&cli.StringFlag{Name: "path", Usage: "Path", Value: "/"},
+					&cli.IntFlag{Name: "port", Usage: "Service port", Required: true},
+					&cli.StringFlag{Name: "serviceName", Usage: "Service name", Required: true},
+					&cli.StringFlag{Name: "composeId", Usage: "Compose ID", Required: true},
+					&cli.StringFlag{Name: "certificateType", Usage: "Certificate type (none/letsencrypt)", Value: "none"},
+					&cli.BoolFlag{Name: "https", Usage: "Enable HTTPS"},
+				},
+				Action: func(c *cli.Context) error {
+					certType := strings.ToLower(c.String("certificateType"))
+					switch certType {
+					case "none", "letsencrypt":
+						// ok
+					default:
+						return fmt.Errorf("invalid certificateType %q, must be one of: none, letsencrypt", certType)
+					}
+
+					client, err := newClientFromCtx(c)
+					if err != nil {
+						return err
+					}
+
+					// A domain for a service the compose file does not define is
+					// accepted by Dokploy but routes nowhere. Only warn, since the
+					// file may be about to change; Dokploy reports a missing
+					// compose app when the domain is saved.
+					if cmp, err := dokploy.GetComposeByID(c.Context, client, c.String("composeId")); err == nil {
+						if file, err := dokploy.ParseComposeFile([]byte(cmp.ComposeFile)); err == nil && !file.HasService(c.String("serviceName")) {
+							fmt.Fprintln(os.Stderr, "Warning:", missingServiceProblem(c.String("serviceName"), file).Message)
+						}
+					}
+
+					id, err := dokploy.CreateOrUpdateDomain(
+						c.Context,
+						client,
+						c.String("id"),
+						c.String("host"),
+						c.String("path"),
+						c.Int("port"),
+						c.String("serviceName"),
+						c.String("composeId"),
+						certType,
+						c.Bool("https"),
+					)
+					if err != nil {
+						return err
+					}
+					// Without --id an existing domain of the compose app may be
+					// updated instead, so the action is only "saved".
+					return printOutput(c, actionOutput("domain", id, "saved", id))
+				},
+			},
+			{
+				Name:  "delete",
+				Usage: "Delete a domain",
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "id", Usage: "Domain ID", Required: true},
+				},
+				Action: func(c *cli.Context) error {
+					client, err := newClientFromCtx(c)
+					if err != nil {
+						return err
+					}
+					id := c.String("id")
+					if err := dokploy.DeleteDomain(c.Context, client, id); err != nil {
+						return err
+					}
+					return printOutput(c, actionOutput("domain", id, "deleted", "Deleted domain "+id))
+				},
+			},
+		},
+	}
+}
+
+// APPLY COMMAND
+
+func applyCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "apply",
+		Usage: "Create or update a project, its environments, compose apps and domains from a stack manifest",
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "file", Aliases: []string{"f"}, Usage: "Path to the stack manifest (YAML)", Required: true, TakesFile: true},
+		},
+		Action: func(c *cli.Context) error {
+			stack, err := dokploy.LoadStack(c.String("file"))
+			if err != nil {
+				return err
+			}
+			client, err := newClientFromCtx(c)
+			if err != nil {
+				return err
+			}
+
+			changes, err := dokploy.ApplyStack(c.Context, client, stack)
+			out := changesOutput(changes)
+			var text strings.Builder
+			counts := map[dokploy.ApplyAction]int{}
+			for _, ch := range changes {
+				counts[ch.Action]++
+				fmt.Fprintf(&text, "%-9s %-11s %s (%s)\n", ch.Action, ch.Resource, ch.Name, ch.ID)
+			}
+			if err == nil {
+				fmt.Fprintf(&text, "Apply complete: %d created, %d updated, %d unchanged",
+					counts[dokploy.ActionCreated], counts[dokploy.ActionUpdated], counts[dokploy.ActionUnchanged])
+			}
+			out.Text = strings.TrimSuffix(text.String(), "\n")
+			// Print the changes made before a failure too, so the user knows
+			// what the partial apply did.
+			if len(changes) > 0 || err == nil {
+				if perr := printOutput(c, out); perr != nil && err == nil {
+					err = perr
+				}
+			}
+			return err
+		},
+	}
+}
+
+// PLAN COMMAND
+
+func planCommand() *cli.Command {
+	return &cli.Command{
+		Name:    "plan",
+		Aliases: []string{"diff"},
+		Usage:   "Show what apply would change, without touching the server (exits 2 on drift)",
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "file", Aliases: []string{"f"}, Usage: "Path to the stack manifest (YAML)", Required: true, TakesFile: true},
+			&cli.BoolFlag{Name: "show-values", Usage: "Print env var values instead of masking them"},
+		},
+		Action: func(c *cli.Context) error {
+			stack, err := dokploy.LoadStack(c.String("file"))
+			if err != nil {
+				return err
+			}
+			client, err := newClientFromCtx(c)
+			if err != nil {
+				return err
+			}
+
+			changes, err := dokploy.PlanStack(c.Context, client, stack)
+			if err != nil {
+				return err
+			}
+			if !c.Bool("show-values") {
+				changes = maskEnvDiffs(changes)
+			}
+			out := changesOutput(changes)
+			var text strings.Builder
+			counts := map[dokploy.ApplyAction]int{}
+			for _, ch := range changes {
+				counts[ch.Action]++
+				printPlannedChange(&text, ch)
+			}
+			fmt.Fprintf(&text, "Plan: %d to create, %d to update, %d unchanged",
+				counts[dokploy.ActionCreated], counts[dokploy.ActionUpdated], counts[dokploy.ActionUnchanged])
+			out.Text = text.String()
+			if err := printOutput(c, out); err != nil {
+				return err
+			}
+
+			if dokploy.HasDrift(changes) {
+				return cli.Exit("", exitDrift)
+			}
+			return nil
+		},
+	}
+}
+
+// changesOutput is the output of apply and plan: one row per resource.
+func changesOutput(changes []dokploy.ApplyChange) output {
+	out := output{
+		Data:    changes,
+		Columns: []string{"ACTION", "RESOURCE", "NAME", "ID"},
+		Rows:    [][]string{},
+	}
+	for _, ch := range changes {
+		if ch.ID != "" {
+			out.IDs = append(out.IDs, ch.ID)
+		}
+		out.Rows = append(out.Rows, []string{string(ch.Action), ch.Resource, ch.Name, ch.ID})
+	}
+	return out
+}
+
+func printPlannedChange(w io.Writer, ch dokploy.ApplyChange) {
+	symbol := " "
+	switch ch.Action {
+	case dokploy.ActionCreated:
+		symbol = "+"
+	case dokploy.ActionUpdated:
+		symbol = "~"
+	}
+	if ch.ID != "" {
+		fmt.Fprintf(w, "%s %-11s %s (%s)\n", symbol, ch.Resource, ch.Name, ch.ID)
+	} else {
+		fmt.Fprintf(w, "%s %-11s %s\n", symbol, ch.Resource, ch.Name)
+	}
+
+	for _, d := range ch.Diffs {
+		switch {
+		case d.Field == "composeFile":
+			fmt.Fprintln(w, "    composeFile:")
+			for _, line := range dokploy.LineDiff(d.Current, d.Desired) {
+				if !strings.HasPrefix(line, "  ") {
+					fmt.Fprintln(w, "      "+line)
+				}
+			}
+		case strings.HasPrefix(d.Field, "env."):
+			fmt.Fprintf(w, "    %s: %s -> %s\n", d.Field, planEnvValue(d.Current), planEnvValue(d.Desired))
+		default:
+			fmt.Fprintf(w, "    %s: %q -> %q\n", d.Field, d.Current, d.Desired)
+		}
+	}
+}
+
+// envMask replaces env var values in a plan unless --show-values is set.
+const envMask = "(sensitive)"
+
+// maskEnvDiffs returns a copy of changes with env var values replaced by
+// envMask. Unset values stay empty.
+func maskEnvDiffs(changes []dokploy.ApplyChange) []dokploy.ApplyChange {
+	masked := make([]dokploy.ApplyChange, len(changes))
+	for i, ch := range changes {
+		masked[i] = ch
+		masked[i].Diffs = make([]dokploy.FieldDiff, len(ch.Diffs))
+		for j, d := range ch.Diffs {
+			if strings.HasPrefix(d.Field, "env.") {
+				if d.Current != "" {
+					d.Current = envMask
+				}
+				if d.Desired != "" {
+					d.Desired = envMask
+				}
+			}
+			masked[i].Diffs[j] = d
+		}
+	}
+	return masked
+}
+
+func planEnvValue(v string) string {
+	switch v {
+	case "":
+		return "(unset)"
+	case envMask:
+		return v
+	default:
+		return fmt.Sprintf("%q", v)
 	}
 }
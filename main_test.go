@@ -0,0 +1,29 @@
+package main
+
+import (
+	"slices"
+	"testing"
+
+	cli "github.com/urfave/cli/v2"
+)
+
+func TestComposeFilePaths_KeepsCommas(t *testing.T) {
+	t.Helper()
+
+	var got []string
+	app := &cli.App{
+		Flags: []cli.Flag{
+			&cli.GenericFlag{Name: "compose-file", Aliases: []string{"f"}, Required: true, Value: &pathList{}},
+		},
+		Action: func(c *cli.Context) error {
+			got = composeFilePaths(c)
+			return nil
+		},
+	}
+	if err := app.Run([]string{"dokploy", "-f", "deploy/a,b.yml", "-f", "prod.yml"}); err != nil {
+		t.Fatalf("Run error: %v", err)
+	}
+	if want := []string{"deploy/a,b.yml", "prod.yml"}; !slices.Equal(got, want) {
+		t.Errorf("paths = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v3"
+
+	cli "github.com/urfave/cli/v2"
+)
+
+// OUTPUT
+
+// Formats accepted by --output. Without --output, commands print their
+// default human-readable output.
+const (
+	formatJSON  = "json"
+	formatYAML  = "yaml"
+	formatTable = "table"
+	formatID    = "id"
+)
+
+var outputFormats = []string{formatJSON, formatYAML, formatTable, formatID}
+
+func outputFlag() cli.Flag {
+	return &cli.StringFlag{
+		Name:    "output",
+		Aliases: []string{"o"},
+		Usage:   "Output format (" + strings.Join(outputFormats, "/") + "); default: human-readable text (or set DOKPLOY_OUTPUT)",
+		EnvVars: []string{"DOKPLOY_OUTPUT"},
+	}
+}
+
+// validateOutput rejects an unknown --output before any command runs, so a
+// typo does not surface only after a resource has been changed.
+func validateOutput(c *cli.Context) error {
+	if f := c.String("output"); f != "" && !slices.Contains(outputFormats, f) {
+		return fmt.Errorf("invalid --output %q, must be one of: %s", f, strings.Join(outputFormats, ", "))
+	}
+	return nil
+}
+
+// output is what a command prints, in every format --output can select.
+type output struct {
+	// Data is encoded by json and yaml. Its JSON field names are the stable
+	// names scripts rely on; yaml uses the same names.
+	Data any
+	// IDs are printed one per line by id.
+	IDs []string
+	// Columns and Rows are printed by table. Without Rows, table prints
+	// the top-level fields of Data as FIELD/VALUE rows.
+	Columns []string
+	Rows    [][]string
+	// Text is printed when --output is not set. Without Text, the table is
+	// printed if there are Rows, and Data as JSON otherwise.
+	Text string
+}
+
+// actionResult is the Data of commands that create, change or delete a
+// resource.
+type actionResult struct {
+	Resource string `json:"resource"`
+	ID       string `json:"id"`
+	Action   string `json:"action"`
+}
+
+// actionOutput is the output of a command that acted on a resource. text is
+// its default output, such as the bare ID for a create.
+func actionOutput(resource, id, action, text string) output {
+	return output{
+		Data: actionResult{Resource: resource, ID: id, Action: action},
+		IDs:  []string{id},
+		Text: text,
+	}
+}
+
+// outputFormat returns the --output format, or "" when it is not set.
+func outputFormat(c *cli.Context) string {
+	return c.String("output")
+}
+
+// printOutput prints out to stdout in the format selected by --output.
+func printOutput(c *cli.Context, out output) error {
+	return writeOutput(os.Stdout, outputFormat(c), out)
+}
+
+func writeOutput(w io.Writer, format string, out output) error {
+	switch format {
+	case "":
+		switch {
+		case out.Text != "":
+			_, err := fmt.Fprintln(w, out.Text)
+			return err
+		case out.Rows != nil:
+			return writeTable(w, out.Columns, out.Rows)
+		}
+		return writeJSON(w, out.Data)
+	case formatJSON:
+		return writeJSON(w, out.Data)
+	case formatYAML:
+		return writeYAML(w, out.Data)
+	case formatTable:
+		if out.Rows != nil {
+			return writeTable(w, out.Columns, out.Rows)
+		}
+		rows, err := fieldRows(out.Data)
+		if err != nil {
+			return err
+		}
+		return writeTable(w, []string{"FIELD", "VALUE"}, rows)
+	case formatID:
+		for _, id := range out.IDs {
+			if _, err := fmt.Fprintln(w, id); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("invalid output format %q", format)
+}
+
+func writeJSON(w io.Writer, data any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(data)
+}
+
+// writeYAML encodes data with its JSON field names, in the same order as
+// writeJSON, by reading the JSON back as a YAML document.
+func writeYAML(w io.Writer, data any) error {
+	node, err := dataNode(data)
+	if err != nil {
+		return err
+	}
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(node); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+func writeTable(w io.Writer, columns []string, rows [][]string) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	if len(columns) > 0 {
+		fmt.Fprintln(tw, strings.Join(columns, "\t"))
+	}
+	for _, row := range rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+// dataNode converts data to a YAML node through its JSON encoding. JSON is
+// valid YAML, so field names and order are kept; styles are reset so the
+// node is written as block YAML rather than JSON.
+func dataNode(data any) (*yaml.Node, error) {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(b, &doc); err != nil {
+		return nil, err
+	}
+	var reset func(n *yaml.Node)
+	reset = func(n *yaml.Node) {
+		n.Style = 0
+		for _, child := range n.Content {
+			reset(child)
+		}
+	}
+	reset(&doc)
+	if doc.Kind == yaml.DocumentNode && len(doc.Content) == 1 {
+		return doc.Content[0], nil
+	}
+	return &doc, nil
+}
+
+// fieldRows lists the top-level fields of a JSON object as FIELD/VALUE rows.
+// Nested values are shown as compact JSON and multi-line strings quoted, so
+// each field stays on one row.
+func fieldRows(data any) ([][]string, error) {
+	node, err := dataNode(data)
+	if err != nil {
+		return nil, err
+	}
+	if node.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("table output is not supported for this command")
+	}
+	rows := make([][]string, 0, len(node.Content)/2)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		var text string
+		switch {
+		case value.Kind == yaml.ScalarNode && value.Tag == "!!null":
+		case value.Kind == yaml.ScalarNode && strings.Contains(value.Value, "\n"):
+			text = strconv.Quote(value.Value)
+		case value.Kind == yaml.ScalarNode:
+			text = value.Value
+		default:
+			var v any
+			if err := value.Decode(&v); err != nil {
+				return nil, err
+			}
+			b, err := json.Marshal(v)
+			if err != nil {
+				return nil, err
+			}
+			text = string(b)
+		}
+		rows = append(rows, []string{key.Value, text})
+	}
+	return rows, nil
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteOutput_Formats(t *testing.T) {
+	t.Helper()
+
+	out := output{
+		Data: map[string]any{"composeId": "cmp-1", "name": "web", "env": "A=1\nB=2", "domains": []string{"a.example.com"}},
+		IDs:  []string{"cmp-1"},
+	}
+	cases := []struct {
+		format string
+		want   string
+	}{
+		{format: formatID, want: "cmp-1\n"},
+		{format: formatJSON, want: "{\n  \"composeId\": \"cmp-1\",\n  \"domains\": [\n    \"a.example.com\"\n  ],\n  \"env\": \"A=1\\nB=2\",\n  \"name\": \"web\"\n}\n"},
+		{format: formatYAML, want: "composeId: cmp-1\ndomains:\n  - a.example.com\nenv: |-\n  A=1\n  B=2\nname: web\n"},
+		{format: formatTable, want: "FIELD      VALUE\ncomposeId  cmp-1\ndomains    [\"a.example.com\"]\nenv        \"A=1\\nB=2\"\nname       web\n"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := writeOutput(&buf, tc.format, out); err != nil {
+				t.Fatalf("writeOutput error: %v", err)
+			}
+			if buf.String() != tc.want {
+				t.Errorf("output =\n%s\nwant\n%s", buf.String(), tc.want)
+			}
+		})
+	}
+}
+
+func TestWriteOutput_DefaultFormat(t *testing.T) {
+	cases := []struct {
+		name string
+		out  output
+		want string
+	}{
+		{name: "text", out: actionOutput("compose", "cmp-1", "deleted", "Deleted compose cmp-1"), want: "Deleted compose cmp-1\n"},
+		{name: "rows", out: output{Columns: []string{"ID", "NAME"}, Rows: [][]string{{"env-1", "production"}}}, want: "ID     NAME\nenv-1  production\n"},
+		{name: "data", out: output{Data: actionResult{Resource: "compose", ID: "cmp-1", Action: "created"}}, want: "{\n  \"resource\": \"compose\",\n  \"id\": \"cmp-1\",\n  \"action\": \"created\"\n}\n"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := writeOutput(&buf, "", tc.out); err != nil {
+				t.Fatalf("writeOutput error: %v", err)
+			}
+			if buf.String() != tc.want {
+				t.Errorf("output = %q, want %q", buf.String(), tc.want)
+			}
+		})
+	}
+}
+
+func TestWriteOutput_YAMLKeepsFieldOrderAndQuotesAmbiguousStrings(t *testing.T) {
+	var buf bytes.Buffer
+	data := projectCreateResult{ProjectID: "123", EnvironmentID: "true"}
+	if err := writeOutput(&buf, formatYAML, output{Data: data}); err != nil {
+		t.Fatalf("writeOutput error: %v", err)
+	}
+	want := "projectId: \"123\"\nenvironmentId: \"true\"\n"
+	if buf.String() != want {
+		t.Errorf("output = %q, want %q", buf.String(), want)
+	}
+}